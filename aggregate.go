@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/NimbleMarkets/ntcharts/linechart/timeserieslinechart"
+)
+
+// aggregateFuncs maps aggregation names to their implementation
+var aggregateFuncs = map[string]func([]float64) float64{
+	"sum": func(values []float64) float64 {
+		total := 0.0
+		for _, v := range values {
+			total += v
+		}
+		return total
+	},
+	"avg": func(values []float64) float64 {
+		total := 0.0
+		for _, v := range values {
+			total += v
+		}
+		return total / float64(len(values))
+	},
+	"min": func(values []float64) float64 {
+		result := math.Inf(1)
+		for _, v := range values {
+			result = math.Min(result, v)
+		}
+		return result
+	},
+	"max": func(values []float64) float64 {
+		result := math.Inf(-1)
+		for _, v := range values {
+			result = math.Max(result, v)
+		}
+		return result
+	},
+}
+
+// aggregateOps is the order the aggregations are cycled through
+var aggregateOps = []string{"sum", "avg", "max", "min"}
+
+// nextAggregateOp returns the aggregation following op in the cycle
+func nextAggregateOp(op string) string {
+	i := slices.Index(aggregateOps, op)
+	return aggregateOps[(i+1)%len(aggregateOps)]
+}
+
+// aggregateGroup returns the name of the aggregated line a series belongs to,
+// e.g. `sum{job="api"}` when grouping by job
+func aggregateGroup(op, fullName string, groupBy []string) string {
+	if len(groupBy) == 0 {
+		return op
+	}
+
+	pairs := make([]string, len(groupBy))
+	for i, label := range groupBy {
+		value, _ := labelValue(fullName, label)
+		pairs[i] = fmt.Sprintf("%s=%q", label, value)
+	}
+	return op + "{" + strings.Join(pairs, ",") + "}"
+}
+
+// aggregateLines combines lines per group and timestamp using the given aggregation.
+// Groups are returned in the order they first appear.
+func aggregateLines(lines []plotLine, op string, groupBy []string) []plotLine {
+	fn, ok := aggregateFuncs[op]
+	if !ok {
+		return lines
+	}
+
+	type group struct {
+		times  []int64
+		values map[int64][]float64
+	}
+	var order []string
+	groups := make(map[string]*group)
+
+	for _, line := range lines {
+		name := aggregateGroup(op, line.name, groupBy)
+		g, exists := groups[name]
+		if !exists {
+			g = &group{values: make(map[int64][]float64)}
+			groups[name] = g
+			order = append(order, name)
+		}
+
+		for _, point := range line.points {
+			ts := point.Time.UnixNano()
+			if _, seen := g.values[ts]; !seen {
+				g.times = append(g.times, ts)
+			}
+			g.values[ts] = append(g.values[ts], point.Value)
+		}
+	}
+
+	result := make([]plotLine, len(order))
+	for i, name := range order {
+		g := groups[name]
+		slices.Sort(g.times)
+
+		points := make([]timeserieslinechart.TimePoint, len(g.times))
+		for j, ts := range g.times {
+			points[j] = timeserieslinechart.TimePoint{
+				Time:  time.Unix(0, ts),
+				Value: fn(g.values[ts]),
+			}
+		}
+		result[i] = plotLine{name: name, colorIdx: i, points: points}
+	}
+
+	return result
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/NimbleMarkets/ntcharts/linechart/timeserieslinechart"
+)
+
+func TestAggregateFuncs(t *testing.T) {
+	values := []float64{4, 1, 7}
+	tests := map[string]float64{
+		"sum": 12,
+		"avg": 4,
+		"min": 1,
+		"max": 7,
+	}
+
+	for op, want := range tests {
+		if got := aggregateFuncs[op](values); math.Abs(got-want) > 1e-9 {
+			t.Fatalf("%s: expected %v, got %v", op, want, got)
+		}
+	}
+}
+
+func TestAggregateGroup(t *testing.T) {
+	name := `http_requests{instance="a",job="api",method="GET"}`
+
+	if got := aggregateGroup("sum", name, nil); got != "sum" {
+		t.Fatalf("expected sum, got %s", got)
+	}
+	if got := aggregateGroup("avg", name, []string{"job"}); got != `avg{job="api"}` {
+		t.Fatalf("unexpected group %s", got)
+	}
+	if got := aggregateGroup("max", name, []string{"job", "zone"}); got != `max{job="api",zone=""}` {
+		t.Fatalf("unexpected group with missing label %s", got)
+	}
+}
+
+func TestAggregateLines(t *testing.T) {
+	t0 := time.Unix(1700000000, 0)
+	t1 := t0.Add(time.Second)
+	lines := []plotLine{
+		{name: `m{job="api",instance="a"}`, points: []timeserieslinechart.TimePoint{{Time: t0, Value: 1}, {Time: t1, Value: 2}}},
+		{name: `m{job="db",instance="b"}`, points: []timeserieslinechart.TimePoint{{Time: t0, Value: 10}}},
+		{name: `m{job="api",instance="c"}`, points: []timeserieslinechart.TimePoint{{Time: t1, Value: 3}, {Time: t0, Value: 4}}},
+	}
+
+	got := aggregateLines(lines, "sum", []string{"job"})
+	if len(got) != 2 {
+		t.Fatalf("expected 2 groups, got %d", len(got))
+	}
+	if got[0].name != `sum{job="api"}` || got[1].name != `sum{job="db"}` {
+		t.Fatalf("unexpected group order %s, %s", got[0].name, got[1].name)
+	}
+	if got[0].colorIdx != 0 || got[1].colorIdx != 1 {
+		t.Fatalf("expected groups to get their own colors")
+	}
+
+	api := got[0].points
+	if len(api) != 2 || !api[0].Time.Equal(t0) || api[0].Value != 5 || !api[1].Time.Equal(t1) || api[1].Value != 5 {
+		t.Fatalf("unexpected api points %v", api)
+	}
+
+	if all := aggregateLines(lines, "max", nil); len(all) != 1 || all[0].points[0].Value != 10 {
+		t.Fatalf("expected a single max line, got %v", all)
+	}
+}
+
+func TestNextAggregateOp(t *testing.T) {
+	op := "sum"
+	var cycle []string
+	for range aggregateOps {
+		op = nextAggregateOp(op)
+		cycle = append(cycle, op)
+	}
+	if want := []string{"avg", "max", "min", "sum"}; !slices.Equal(cycle, want) {
+		t.Fatalf("expected the cycle %v, got %v", want, cycle)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/NimbleMarkets/ntcharts/canvas"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// annotation marks an event on the time axis, e.g. a deploy
+type annotation struct {
+	Time  time.Time
+	Label string
+}
+
+// annotationStyle draws the markers and labels of annotations
+var annotationStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("220"))
+
+// annotationColumn returns the canvas column of a time on the chart, if it is
+// within the viewed time range
+func (m *Model) annotationColumn(t time.Time) (int, bool) {
+	sec := float64(t.UnixNano()) / 1e9
+	minX, maxX := m.chart.ViewMinX(), m.chart.ViewMaxX()
+	if sec < minX || sec > maxX || maxX <= minX {
+		return 0, false
+	}
+	col := (sec - minX) / (maxX - minX) * float64(m.chart.GraphWidth())
+	return m.chart.Origin().X + int(math.Round(col)), true
+}
+
+// markAnnotations draws a vertical marker for every annotation within the
+// viewed time range, behind the lines and labeled in the top row
+func (m *Model) markAnnotations() {
+	origin := m.chart.Origin()
+	for _, a := range m.annotations {
+		x, ok := m.annotationColumn(a.Time)
+		if !ok || x <= origin.X {
+			continue
+		}
+		for y := 0; y < origin.Y; y++ {
+			p := canvas.Point{X: x, Y: y}
+			if cell := m.chart.Canvas.Cell(p); cell.Rune == 0 || cell.Rune == ' ' {
+				m.chart.Canvas.SetCell(p, canvas.NewCellWithStyle('┊', annotationStyle))
+			}
+		}
+		for i, r := range []rune(a.Label) {
+			if x+1+i >= m.chart.Width() {
+				break
+			}
+			m.chart.Canvas.SetCell(canvas.Point{X: x + 1 + i, Y: 0}, canvas.NewCellWithStyle(r, annotationStyle))
+		}
+	}
+}
+
+// updateAnnotationLabel handles key presses while typing the label of a new
+// annotation, which is placed at the time annotating started
+func (m *Model) updateAnnotationLabel(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.annotating = false
+	case tea.KeyEnter:
+		m.annotating = false
+		m.annotations = append(m.annotations, annotation{Time: m.annotationTime, Label: m.annotationLabel})
+		m.drawChart()
+	case tea.KeyBackspace:
+		if runes := []rune(m.annotationLabel); len(runes) > 0 {
+			m.annotationLabel = string(runes[:len(runes)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.annotationLabel += string(msg.Runes)
+	}
+}
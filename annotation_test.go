@@ -0,0 +1,100 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NimbleMarkets/ntcharts/canvas"
+	tea "github.com/charmbracelet/bubbletea"
+	zone "github.com/lrstanley/bubblezone"
+)
+
+func TestAnnotations(t *testing.T) {
+	zone.NewGlobal()
+	start := time.Now().Add(-10 * time.Second)
+	m := NewModel("http://localhost", "up", time.Second)
+	for i := range 10 {
+		model, _ := m.Update(MetricsMsg{
+			Samples: []MetricSample{{FullName: "up", Value: float64(i % 2)}},
+			Time:    start.Add(time.Duration(i) * time.Second),
+		})
+		m = model.(Model)
+	}
+
+	for _, key := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune("M")},
+		{Type: tea.KeyRunes, Runes: []rune("deploy")},
+		{Type: tea.KeyEnter},
+	} {
+		model, _ := m.Update(key)
+		m = model.(Model)
+	}
+	if len(m.annotations) != 1 || m.annotations[0].Label != "deploy" {
+		t.Fatalf("expected the typed annotation, got %+v", m.annotations)
+	}
+
+	// Place it within the captured time range to see it drawn
+	m.annotations[0].Time = start.Add(5 * time.Second)
+	m.drawChart()
+	x, ok := m.annotationColumn(m.annotations[0].Time)
+	if !ok {
+		t.Fatal("expected the annotation within the viewed time range")
+	}
+	label := ""
+	for i := range len("deploy") {
+		label += string(m.chart.Canvas.Cell(canvas.Point{X: x + 1 + i, Y: 0}).Rune)
+	}
+	if label != "deploy" {
+		t.Fatalf("expected the label next to the marker, got %q", label)
+	}
+
+	// Annotations scroll out of view with the time axis
+	m.chart.SetViewXRange(float64(start.Add(7*time.Second).Unix()), float64(start.Add(9*time.Second).Unix()))
+	if _, ok := m.annotationColumn(m.annotations[0].Time); ok {
+		t.Fatal("expected the annotation outside of the viewed time range")
+	}
+
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("U")})
+	if len(model.(Model).annotations) != 0 {
+		t.Fatal("expected the annotations to be cleared")
+	}
+}
+
+func TestClearAnnotationsWithoutLines(t *testing.T) {
+	zone.NewGlobal()
+	start := time.Now().Add(-10 * time.Second)
+	m := NewModel("http://localhost", "up", time.Second)
+	for i := range 10 {
+		model, _ := m.Update(MetricsMsg{
+			Samples: []MetricSample{{FullName: "up", Value: 1}},
+			Time:    start.Add(time.Duration(i) * time.Second),
+		})
+		m = model.(Model)
+	}
+
+	// Hide the only series, so there are no lines to draw
+	for _, key := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune("s")},
+		{Type: tea.KeySpace, Runes: []rune(" ")},
+		{Type: tea.KeyEnter},
+		{Type: tea.KeyRunes, Runes: []rune("M")},
+		{Type: tea.KeyRunes, Runes: []rune("deploy")},
+		{Type: tea.KeyEnter},
+	} {
+		model, _ := m.Update(key)
+		m = model.(Model)
+	}
+	m.annotations[0].Time = start.Add(5 * time.Second)
+	m.redrawChart()
+	x, _ := m.annotationColumn(m.annotations[0].Time)
+	marker := canvas.Point{X: x, Y: 1}
+	if r := m.chart.Canvas.Cell(marker).Rune; r != '┊' {
+		t.Fatalf("expected the annotation marker, got %q", r)
+	}
+
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("U")})
+	m = model.(Model)
+	if r := m.chart.Canvas.Cell(marker).Rune; r == '┊' {
+		t.Fatal("expected the marker to be cleared from the chart")
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Bounds of the delay between retries of failed scrapes
+const (
+	minRetryDelay = time.Second
+	maxRetryDelay = time.Minute
+)
+
+// retryDelay returns the delay before retrying after a number of consecutive
+// failed scrapes. It starts below the interval and doubles with every failure.
+func retryDelay(failures int, interval time.Duration) time.Duration {
+	delay := min(minRetryDelay, interval)
+	for i := 1; i < failures && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+// scheduleRetry replaces the regular tick with a retry of the failed scrape.
+// The tick of the retry schedules the regular interval again.
+func (m *Model) scheduleRetry() tea.Cmd {
+	// Paused and replayed charts don't scrape on their own
+	if m.paused || m.replayFrames != nil {
+		return nil
+	}
+	delay := retryDelay(m.emptyScrapes, m.interval)
+	m.retryAt = time.Now().Add(delay)
+	m.tickGen++
+	return tickCmd(delay, m.tickGen)
+}
+
+// retryText tells when the failed scrape is tried again
+func (m *Model) retryText(now time.Time) string {
+	if m.paused {
+		return "Press f to retry"
+	}
+	if m.retryAt.IsZero() {
+		return fmt.Sprintf("Retrying every %s", m.interval)
+	}
+	wait := max(m.retryAt.Sub(now), 0).Round(time.Second)
+	return fmt.Sprintf("Retry %d in %s", m.emptyScrapes, wait)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	zone "github.com/lrstanley/bubblezone"
+)
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		failures int
+		interval time.Duration
+		want     time.Duration
+	}{
+		{1, 5 * time.Second, time.Second},
+		{2, 5 * time.Second, 2 * time.Second},
+		{4, 5 * time.Second, 8 * time.Second},
+		{20, 5 * time.Second, maxRetryDelay},
+		{1, 500 * time.Millisecond, 500 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := retryDelay(tt.failures, tt.interval); got != tt.want {
+			t.Fatalf("retryDelay(%d, %s) = %s, want %s", tt.failures, tt.interval, got, tt.want)
+		}
+	}
+}
+
+func TestFailedScrapeSchedulesRetry(t *testing.T) {
+	zone.NewGlobal()
+	m := NewModel("http://localhost", "up", 5*time.Second)
+
+	model, cmd := m.Update(MetricsMsg{Err: errors.New("connection refused")})
+	m = model.(Model)
+	if cmd == nil || m.retryAt.IsZero() || m.tickGen != 1 {
+		t.Fatalf("expected a retry replacing the regular tick, got gen %d", m.tickGen)
+	}
+
+	// The regular tick scheduled before the failure is dropped
+	if _, cmd := m.Update(TickMsg{Gen: 0}); cmd != nil {
+		t.Fatal("expected the stale tick to be dropped")
+	}
+
+	model, _ = m.Update(MetricsMsg{Samples: []MetricSample{{FullName: "up", Value: 1}}, Time: time.Now()})
+	m = model.(Model)
+	if !m.retryAt.IsZero() || m.emptyScrapes != 0 {
+		t.Fatal("expected a successful scrape to end the backoff")
+	}
+}
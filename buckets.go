@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// bucket is a bucket of a histogram at one scrape
+type bucket struct {
+	le    string  // Upper bound as exposed, e.g. "0.5" or "+Inf"
+	bound float64 // Parsed upper bound
+	count float64 // Observations within the bucket, without those of smaller buckets
+}
+
+// bucketGroup are the buckets of one histogram, told apart by the labels besides le
+type bucketGroup struct {
+	name    string
+	buckets []bucket
+}
+
+// isBucketSeries reports whether a series is a bucket of a histogram
+func isBucketSeries(fullName string) bool {
+	base, _, _ := strings.Cut(fullName, "{")
+	_, ok := labelValue(fullName, "le")
+	return ok && strings.HasSuffix(base, "_bucket")
+}
+
+// bucketGroups groups the cumulative values of bucket series by histogram. The
+// buckets are sorted by bound (with +Inf last) and hold their own counts.
+func bucketGroups(values map[string]float64) []bucketGroup {
+	byName := make(map[string][]bucket)
+	for name, value := range values {
+		le, ok := labelValue(name, "le")
+		if !ok || !isBucketSeries(name) {
+			continue
+		}
+		bound, err := strconv.ParseFloat(le, 64)
+		if err != nil {
+			continue
+		}
+		group := withoutLabel(name, "le")
+		byName[group] = append(byName[group], bucket{le: le, bound: bound, count: value})
+	}
+
+	groups := make([]bucketGroup, 0, len(byName))
+	for name, buckets := range byName {
+		sort.Slice(buckets, func(i, j int) bool { return buckets[i].bound < buckets[j].bound })
+		// Bucket values are cumulative, a smaller bucket can't have more observations
+		// unless the scrape caught the histogram while it was being updated
+		prev := 0.0
+		for i := range buckets {
+			cumulative := buckets[i].count
+			buckets[i].count = math.Max(cumulative-prev, 0)
+			prev = cumulative
+		}
+		groups = append(groups, bucketGroup{name: name, buckets: buckets})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].name < groups[j].name })
+	return groups
+}
+
+// hasBuckets reports whether the current metric is a histogram with bucket series
+func (m *Model) hasBuckets() bool {
+	for _, series := range m.seriesList {
+		if isBucketSeries(series.name) {
+			return true
+		}
+	}
+	return false
+}
+
+// bucketsView renders the bucket counts of the histograms of the current metric
+// as of the latest scrape, or the frame stepped back to
+func (m *Model) bucketsView() string {
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render("Bucket distribution of " + m.metricName))
+	sb.WriteString("\n\n")
+
+	values := make(map[string]float64)
+	for _, series := range m.seriesList {
+		if value, ok := m.currentValue(series.name); ok {
+			values[series.name] = value
+		}
+	}
+	groups := bucketGroups(values)
+	if len(groups) == 0 {
+		sb.WriteString(listItemStyle.Render("No histogram buckets captured yet"))
+		sb.WriteString("\n")
+	}
+
+	barWidth := max(m.termWidth-40, 10)
+	barStyle := lipgloss.NewStyle().Foreground(accentColor)
+	for _, group := range groups {
+		if len(groups) > 1 {
+			sb.WriteString(listItemStyle.Render(labelPairs(group.name)))
+			sb.WriteString("\n")
+		}
+
+		maxCount := 0.0
+		for _, b := range group.buckets {
+			maxCount = math.Max(maxCount, b.count)
+		}
+		for _, b := range group.buckets {
+			bar := ""
+			if maxCount > 0 {
+				bar = strings.Repeat("█", int(b.count/maxCount*float64(barWidth)))
+			}
+			count := strconv.FormatFloat(b.count, 'g', -1, 64)
+			sb.WriteString(listItemStyle.Render(fmt.Sprintf("≤ %-10s │%s %s", b.le, barStyle.Render(bar), count)))
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString(helpStyle.Render("Press B, Esc or q to close"))
+	return sb.String()
+}
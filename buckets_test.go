@@ -0,0 +1,91 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	zone "github.com/lrstanley/bubblezone"
+)
+
+func TestIsBucketSeries(t *testing.T) {
+	tests := map[string]bool{
+		`http_duration_seconds_bucket{le="0.5"}`:             true,
+		`http_duration_seconds_bucket{code="200",le="+Inf"}`: true,
+		`http_duration_seconds_count{code="200"}`:            false,
+		`http_duration_seconds_bucket{code="200"}`:           false,
+		`queue_le{le="1"}`:                                   false,
+		`http_duration_seconds_bucket_total{le="1"}`:         false,
+	}
+	for name, want := range tests {
+		if got := isBucketSeries(name); got != want {
+			t.Errorf("isBucketSeries(%s) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestBucketGroups(t *testing.T) {
+	groups := bucketGroups(map[string]float64{
+		`d_bucket{code="200",le="+Inf"}`: 10,
+		`d_bucket{code="200",le="0.1"}`:  4,
+		`d_bucket{code="200",le="1"}`:    9,
+		`d_bucket{code="500",le="1"}`:    2,
+		`d_bucket{code="500",le="+Inf"}`: 1,
+		`d_count{code="200"}`:            10,
+	})
+	if len(groups) != 2 || groups[0].name != `d_bucket{code="200"}` || groups[1].name != `d_bucket{code="500"}` {
+		t.Fatalf("expected a group per code, got %+v", groups)
+	}
+
+	var les []string
+	var counts []float64
+	for _, b := range groups[0].buckets {
+		les = append(les, b.le)
+		counts = append(counts, b.count)
+	}
+	if strings.Join(les, " ") != "0.1 1 +Inf" || counts[0] != 4 || counts[1] != 5 || counts[2] != 1 {
+		t.Fatalf("expected sorted buckets with their own counts, got %v %v", les, counts)
+	}
+	// A scrape taken mid-update may show fewer observations in a larger bucket
+	if groups[1].buckets[1].count != 0 {
+		t.Fatalf("expected no negative counts, got %v", groups[1].buckets[1].count)
+	}
+}
+
+func TestBucketsView(t *testing.T) {
+	zone.NewGlobal()
+	m := NewModel("http://localhost", "d_bucket", time.Second)
+	bucketsKey := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("B")}
+	model, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	model, _ = model.Update(bucketsKey)
+	if m = model.(Model); m.showBuckets {
+		t.Fatal("expected no buckets without series")
+	}
+
+	start := time.Unix(1000, 0)
+	for i, counts := range [][2]float64{{1, 2}, {3, 5}} {
+		model, _ = m.Update(MetricsMsg{
+			Samples: []MetricSample{
+				{FullName: `d_bucket{le="+Inf"}`, Value: counts[1]},
+				{FullName: `d_bucket{le="0.1"}`, Value: counts[0]},
+			},
+			Time: start.Add(time.Duration(i) * time.Second),
+		})
+		m = model.(Model)
+	}
+	model, _ = m.Update(bucketsKey)
+	if m = model.(Model); !m.showBuckets {
+		t.Fatalf("expected the bucket series to be detected, got status %q", m.status)
+	}
+
+	// The buckets are shown in order of their bounds with the latest counts per bucket
+	view := m.View()
+	low, high := strings.Index(view, "≤ 0.1"), strings.Index(view, "≤ +Inf")
+	if low == -1 || high == -1 || low > high {
+		t.Fatalf("expected the buckets in order of their bounds, got\n%s", view)
+	}
+	if !strings.Contains(view[low:high], " 3") || !strings.Contains(view[high:], " 2") {
+		t.Fatalf("expected the latest bucket counts only, got\n%s", view)
+	}
+}
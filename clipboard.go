@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/atotto/clipboard"
+)
+
+// debugLogging reports whether logs go to debug.log, as enabled by the DEBUG
+// environment variable. Otherwise they would garble the screen.
+func debugLogging() bool {
+	return len(os.Getenv("DEBUG")) > 0
+}
+
+// copyValue copies the current value of a series to the clipboard. Without
+// clipboard access (e.g. over SSH) the value is shown in the status line instead.
+func (m *Model) copyValue(name string) {
+	value, ok := m.currentValue(name)
+	if !ok {
+		m.status = fmt.Sprintf("No value for %s yet", seriesDisplayName(name))
+		return
+	}
+
+	text := strconv.FormatFloat(value, 'g', -1, 64)
+	if err := clipboard.WriteAll(text); err != nil {
+		m.status = fmt.Sprintf("Clipboard unavailable, %s = %s", seriesDisplayName(name), text)
+		return
+	}
+	m.status = fmt.Sprintf("Copied %s = %s", seriesDisplayName(name), text)
+}
+
+// targetSeries returns the series a chart action applies to: the one
+// hovered in the legend, or the only visible one
+func (m *Model) targetSeries() (string, bool) {
+	if m.hoveredSeries >= 0 && m.hoveredSeries < len(m.seriesList) {
+		return m.seriesList[m.hoveredSeries].name, true
+	}
+
+	name := ""
+	for _, series := range m.seriesList {
+		if !series.checked {
+			continue
+		}
+		if name != "" {
+			return "", false
+		}
+		name = series.name
+	}
+	return name, name != ""
+}
+
+// shareText describes what the chart shows to share it: the scraped URLs
+// (without credentials), the metric and the visible series
+func (m *Model) shareText() string {
+	var urls []string
+	for _, u := range m.urls {
+		urls = append(urls, redactURL(u))
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "URL: %s\n", strings.Join(urls, ", "))
+	if m.fetch.query != "" {
+		fmt.Fprintf(&sb, "Query: %s\n", m.fetch.query)
+	} else {
+		fmt.Fprintf(&sb, "Metric: %s\n", m.metricName)
+	}
+	for _, series := range m.seriesList {
+		if series.checked {
+			fmt.Fprintf(&sb, "Series: %s\n", series.name)
+		}
+	}
+	return sb.String()
+}
+
+// copyShareText copies the description of the chart to the clipboard. Without
+// clipboard access it is written to the debug log, if there is one.
+func (m *Model) copyShareText() {
+	text := m.shareText()
+	if err := clipboard.WriteAll(text); err == nil {
+		m.status = "Copied URL, metric and visible series!"
+		return
+	}
+	if !debugLogging() {
+		m.status = "Clipboard unavailable, run with DEBUG=1 to write it to debug.log instead"
+		return
+	}
+	log.Printf("share:\n%s", text)
+	m.status = "Clipboard unavailable, wrote URL, metric and visible series to debug.log"
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// completionTimeout bounds a request made for shell completion, which blocks the shell
+const completionTimeout = 2 * time.Second
+
+// completeMetrics offers the metric names of the URL given as first argument as
+// completions of --metric. Unreachable URLs simply offer nothing.
+func completeMetrics(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	cfg, err := newFetchConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	if cfg.client.Timeout == 0 || cfg.client.Timeout > completionTimeout {
+		cfg.client.Timeout = completionTimeout
+	}
+
+	metrics, err := fetchAllMetrics(cfg, args[0])
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var completions []string
+	for _, metric := range metrics {
+		if strings.HasPrefix(metric, toComplete) {
+			completions = append(completions, metric)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
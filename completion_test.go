@@ -0,0 +1,34 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestCompleteMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("http_requests_total 1\nhttp_errors_total 2\nprocess_cpu_seconds_total 3\n"))
+	}))
+	defer server.Close()
+
+	completions, directive := completeMetrics(rootCmd, []string{server.URL}, "http_")
+	if want := []string{"http_errors_total", "http_requests_total"}; !reflect.DeepEqual(completions, want) {
+		t.Fatalf("expected %v, got %v", want, completions)
+	}
+	if directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Fatalf("unexpected directive %v", directive)
+	}
+
+	// Unreachable URLs offer nothing instead of failing
+	server.Close()
+	if completions, _ := completeMetrics(rootCmd, []string{server.URL}, ""); completions != nil {
+		t.Fatalf("expected no completions, got %v", completions)
+	}
+	if completions, _ := completeMetrics(rootCmd, nil, ""); completions != nil {
+		t.Fatalf("expected no completions without URL, got %v", completions)
+	}
+}
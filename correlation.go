@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/NimbleMarkets/ntcharts/linechart/timeserieslinechart"
+)
+
+// alignSeries pairs every point of a with the point of b that is nearest in
+// time, skipping points without a partner within the given tolerance.
+// Both series must be ordered by time.
+func alignSeries(a, b []timeserieslinechart.TimePoint, tolerance time.Duration) (xs, ys []float64) {
+	j := 0
+	for _, p := range a {
+		// Advance while the next point of b is at least as close
+		for j+1 < len(b) && absDuration(b[j+1].Time.Sub(p.Time)) <= absDuration(b[j].Time.Sub(p.Time)) {
+			j++
+		}
+		if j < len(b) && absDuration(b[j].Time.Sub(p.Time)) <= tolerance {
+			xs = append(xs, p.Value)
+			ys = append(ys, b[j].Value)
+		}
+	}
+	return xs, ys
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// pearson returns the Pearson correlation coefficient of two equally long
+// samples. It is undefined for fewer than two pairs or a constant sample.
+func pearson(xs, ys []float64) (float64, bool) {
+	n := len(xs)
+	if n < 2 || n != len(ys) {
+		return 0, false
+	}
+
+	var meanX, meanY float64
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= float64(n)
+	meanY /= float64(n)
+
+	var cov, varX, varY float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return 0, false
+	}
+	return cov / math.Sqrt(varX*varY), true
+}
+
+// toggleCorrelated marks or unmarks a series for correlation, dropping the
+// earlier mark when a third series is marked
+func (m *Model) toggleCorrelated(name string) {
+	if i := slices.Index(m.correlated, name); i != -1 {
+		m.correlated = slices.Delete(m.correlated, i, i+1)
+		return
+	}
+	m.correlated = append(m.correlated, name)
+	if len(m.correlated) > 2 {
+		m.correlated = m.correlated[1:]
+	}
+}
+
+// visiblePoints returns the points of a series within the viewed time range
+func (m *Model) visiblePoints(name string) []timeserieslinechart.TimePoint {
+	var points []timeserieslinechart.TimePoint
+	for _, p := range m.windowPoints(name) {
+		t := float64(p.Time.Unix())
+		if t >= m.chart.ViewMinX() && t <= m.chart.ViewMaxX() {
+			points = append(points, p)
+		}
+	}
+	return points
+}
+
+// correlationView renders the correlation of the two marked series
+func (m *Model) correlationView() string {
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render("Correlation"))
+	sb.WriteString("\n\n")
+
+	if len(m.correlated) < 2 {
+		sb.WriteString(listItemStyle.Render("Mark two series with x in the series selection (s) to correlate them"))
+		sb.WriteString("\n")
+	} else {
+		a, b := m.correlated[0], m.correlated[1]
+		sb.WriteString(listItemStyle.Render("A: " + seriesDisplayName(a)))
+		sb.WriteString("\n")
+		sb.WriteString(listItemStyle.Render("B: " + seriesDisplayName(b)))
+		sb.WriteString("\n\n")
+
+		// Scrapes of both series share timestamps, but allow for jitter between them
+		xs, ys := alignSeries(m.visiblePoints(a), m.visiblePoints(b), m.interval/2)
+		if r, ok := pearson(xs, ys); ok {
+			sb.WriteString(listItemStyle.Render(fmt.Sprintf("Pearson r = %.3f over %d samples", r, len(xs))))
+		} else {
+			sb.WriteString(listItemStyle.Render(fmt.Sprintf("Not enough varying samples to correlate (%d matched)", len(xs))))
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(helpStyle.Render("Press x, Esc or q to close"))
+	return sb.String()
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/NimbleMarkets/ntcharts/linechart/timeserieslinechart"
+)
+
+func TestAlignSeries(t *testing.T) {
+	start := time.Now()
+	at := func(offset time.Duration, v float64) timeserieslinechart.TimePoint {
+		return timeserieslinechart.TimePoint{Time: start.Add(offset), Value: v}
+	}
+	a := []timeserieslinechart.TimePoint{at(0, 1), at(2*time.Second, 2), at(4*time.Second, 3), at(10*time.Second, 4)}
+	b := []timeserieslinechart.TimePoint{at(100*time.Millisecond, 10), at(1900*time.Millisecond, 20), at(4200*time.Millisecond, 30)}
+
+	xs, ys := alignSeries(a, b, time.Second)
+	if !reflect.DeepEqual(xs, []float64{1, 2, 3}) || !reflect.DeepEqual(ys, []float64{10, 20, 30}) {
+		t.Fatalf("expected nearest points to be paired, got %v and %v", xs, ys)
+	}
+}
+
+func TestPearson(t *testing.T) {
+	if r, ok := pearson([]float64{1, 2, 3}, []float64{2, 4, 6}); !ok || math.Abs(r-1) > 1e-9 {
+		t.Fatalf("expected perfect correlation, got %v (%v)", r, ok)
+	}
+	if r, ok := pearson([]float64{1, 2, 3}, []float64{3, 2, 1}); !ok || math.Abs(r+1) > 1e-9 {
+		t.Fatalf("expected perfect anti-correlation, got %v (%v)", r, ok)
+	}
+	if _, ok := pearson([]float64{1, 2, 3}, []float64{5, 5, 5}); ok {
+		t.Fatal("expected a constant series to be undefined")
+	}
+	if _, ok := pearson([]float64{1}, []float64{1}); ok {
+		t.Fatal("expected a single pair to be undefined")
+	}
+}
+
+func TestToggleCorrelated(t *testing.T) {
+	m := NewModel("http://localhost", "metric", time.Second)
+	m.toggleCorrelated("a")
+	m.toggleCorrelated("b")
+	m.toggleCorrelated("c")
+	if !reflect.DeepEqual(m.correlated, []string{"b", "c"}) {
+		t.Fatalf("expected the oldest mark to be dropped, got %v", m.correlated)
+	}
+	m.toggleCorrelated("b")
+	if !reflect.DeepEqual(m.correlated, []string{"c"}) {
+		t.Fatalf("expected b to be unmarked, got %v", m.correlated)
+	}
+}
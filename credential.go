@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// execCredential obtains a bearer token from the output of a command, like
+// kubectl's exec credential plugins. Tokens are cached for ttl or until the
+// expiration the command reports.
+type execCredential struct {
+	command string
+	ttl     time.Duration
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// execCredentialOutput is the kubectl ExecCredential format
+type execCredentialOutput struct {
+	Status struct {
+		Token               string    `json:"token"`
+		ExpirationTimestamp time.Time `json:"expirationTimestamp"`
+	} `json:"status"`
+}
+
+// Token returns the cached token, running the command again once it expired
+func (c *execCredential) Token() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && time.Now().Before(c.expires) {
+		return c.token, nil
+	}
+
+	// Never fall back to a stale token if the command fails
+	c.token = ""
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", c.command)
+	} else {
+		cmd = exec.Command("sh", "-c", c.command)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("credential command failed: %w: %s", err, msg)
+		}
+		return "", fmt.Errorf("credential command failed: %w", err)
+	}
+
+	token, expires, err := parseCredentialOutput(out)
+	if err != nil {
+		return "", err
+	}
+	if expires.IsZero() {
+		expires = time.Now().Add(c.ttl)
+	}
+
+	c.token = token
+	c.expires = expires
+	return token, nil
+}
+
+// parseCredentialOutput reads the token from either plain text or an ExecCredential JSON document
+func parseCredentialOutput(out []byte) (string, time.Time, error) {
+	trimmed := bytes.TrimSpace(out)
+	if !bytes.HasPrefix(trimmed, []byte("{")) {
+		if len(trimmed) == 0 {
+			return "", time.Time{}, fmt.Errorf("credential command returned no token")
+		}
+		return string(trimmed), time.Time{}, nil
+	}
+
+	var cred execCredentialOutput
+	if err := json.Unmarshal(trimmed, &cred); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to parse credential command output: %w", err)
+	}
+	if cred.Status.Token == "" {
+		return "", time.Time{}, fmt.Errorf("credential command returned no status.token")
+	}
+	return cred.Status.Token, cred.Status.ExpirationTimestamp, nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCredentialOutput(t *testing.T) {
+	token, expires, err := parseCredentialOutput([]byte("abc123\n"))
+	if err != nil || token != "abc123" || !expires.IsZero() {
+		t.Fatalf("expected plain token, got %q %v %v", token, expires, err)
+	}
+
+	token, expires, err = parseCredentialOutput([]byte(`{"kind":"ExecCredential","status":{"token":"xyz","expirationTimestamp":"2030-01-02T03:04:05Z"}}`))
+	if err != nil || token != "xyz" || !expires.Equal(time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Fatalf("expected ExecCredential token, got %q %v %v", token, expires, err)
+	}
+
+	if _, _, err := parseCredentialOutput([]byte("  \n")); err == nil {
+		t.Fatal("expected error for empty output")
+	}
+	if _, _, err := parseCredentialOutput([]byte(`{"status":{}}`)); err == nil {
+		t.Fatal("expected error for missing status.token")
+	}
+}
+
+func TestExecCredentialCaching(t *testing.T) {
+	counter := t.TempDir() + "/count"
+	cred := &execCredential{command: "echo x >> " + counter + " && wc -l < " + counter, ttl: time.Hour}
+
+	first, err := cred.Token()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, _ := cred.Token()
+	if first != "1" || second != "1" {
+		t.Fatalf("expected the token to be cached, got %q and %q", first, second)
+	}
+
+	cred.ttl = 0
+	cred.expires = time.Time{}
+	if third, _ := cred.Token(); third != "2" {
+		t.Fatalf("expected the command to run again after expiry, got %q", third)
+	}
+}
+
+func TestFetchWithExecCredential(t *testing.T) {
+	scraped := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scraped = true
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("metric_a 1\n"))
+	}))
+	defer server.Close()
+
+	cfg := fetchConfig{credential: &execCredential{command: "echo s3cret"}}
+	if _, err := fetchAllMetrics(cfg, server.URL); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	scraped = false
+	cfg = fetchConfig{credential: &execCredential{command: "echo denied >&2; exit 3"}}
+	_, err := fetchAllMetrics(cfg, server.URL)
+	if err == nil || !strings.Contains(err.Error(), "credential command failed") || !strings.Contains(err.Error(), "denied") {
+		t.Fatalf("expected the command failure to be reported, got %v", err)
+	}
+	if scraped {
+		t.Fatal("expected no scrape when the credential command fails")
+	}
+}
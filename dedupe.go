@@ -0,0 +1,47 @@
+package main
+
+import "fmt"
+
+// dupPolicy decides the value of a series that a scrape lists more than once
+type dupPolicy string
+
+const (
+	dupLast  dupPolicy = "last"  // Keep the value listed last
+	dupFirst dupPolicy = "first" // Keep the value listed first
+	dupSum   dupPolicy = "sum"   // Add up all values
+)
+
+// validate reports an error for an unknown policy. An empty policy keeps the last value.
+func (p dupPolicy) validate() error {
+	switch p {
+	case dupLast, dupFirst, dupSum, "":
+		return nil
+	default:
+		return fmt.Errorf("invalid duplicate policy %q (expected last, first or sum)", string(p))
+	}
+}
+
+// dedupeSamples merges the samples of series listed more than once in a scrape,
+// e.g. by buggy exporters. The order of first appearance is kept.
+func dedupeSamples(samples []MetricSample, policy dupPolicy) []MetricSample {
+	var result []MetricSample
+	index := make(map[string]int, len(samples))
+	for _, sample := range samples {
+		i, exists := index[sample.FullName]
+		if !exists {
+			index[sample.FullName] = len(result)
+			result = append(result, sample)
+			continue
+		}
+
+		switch policy {
+		case dupFirst:
+			// The value seen first is already kept
+		case dupSum:
+			result[i].Value += sample.Value
+		default:
+			result[i].Value = sample.Value
+		}
+	}
+	return result
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestDedupeSamples(t *testing.T) {
+	samples := []MetricSample{
+		{FullName: `up{instance="a"}`, Value: 1},
+		{FullName: `up{instance="b"}`, Value: 5},
+		{FullName: `up{instance="a"}`, Value: 2},
+	}
+
+	tests := []struct {
+		policy dupPolicy
+		want   float64
+	}{
+		{dupLast, 2},
+		{"", 2},
+		{dupFirst, 1},
+		{dupSum, 3},
+	}
+	for _, tt := range tests {
+		got := dedupeSamples(samples, tt.policy)
+		if len(got) != 2 || got[0].FullName != `up{instance="a"}` || got[1].FullName != `up{instance="b"}` {
+			t.Fatalf("%s: expected one sample per series in order, got %v", tt.policy, got)
+		}
+		if got[0].Value != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.policy, tt.want, got[0].Value)
+		}
+	}
+
+	if samples[0].Value != 1 {
+		t.Fatalf("expected the input to stay untouched, got %v", samples)
+	}
+}
+
+func TestDupPolicyValidate(t *testing.T) {
+	if err := dupPolicy("max").validate(); err == nil {
+		t.Fatal("expected an error for an unknown policy")
+	}
+	if err := dupSum.validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
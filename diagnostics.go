@@ -0,0 +1,109 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"sort"
+	"strings"
+	"time"
+)
+
+// connDiagnostics holds connection details of a single scrape
+type connDiagnostics struct {
+	RemoteAddr  string
+	Duration    time.Duration // Time until the response headers arrived
+	Status      string
+	TLSVersion  string
+	CipherSuite string
+	CertSubject string
+	CertExpiry  time.Time
+	Header      http.Header
+}
+
+// trace returns the request with a trace that records the remote address
+func (d *connDiagnostics) trace(req *http.Request) *http.Request {
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Conn != nil {
+				d.RemoteAddr = info.Conn.RemoteAddr().String()
+			}
+		},
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+}
+
+// record stores the details of the response
+func (d *connDiagnostics) record(resp *http.Response, duration time.Duration) {
+	d.Duration = duration
+	if resp == nil {
+		return
+	}
+
+	d.Status = resp.Status
+	d.Header = resp.Header.Clone()
+
+	if resp.TLS != nil {
+		d.TLSVersion = tls.VersionName(resp.TLS.Version)
+		d.CipherSuite = tls.CipherSuiteName(resp.TLS.CipherSuite)
+		if len(resp.TLS.PeerCertificates) > 0 {
+			cert := resp.TLS.PeerCertificates[0]
+			d.CertSubject = cert.Subject.String()
+			d.CertExpiry = cert.NotAfter
+		}
+	}
+}
+
+// diagnosticsView renders the connection details of the last scrape
+func (m *Model) diagnosticsView() string {
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render("Diagnostics of the last scrape"))
+	sb.WriteString("\n\n")
+
+	d := m.diagnostics
+	if d == nil {
+		sb.WriteString(listItemStyle.Render("No scrape has completed yet"))
+		sb.WriteString("\n")
+	} else {
+		row := func(name, value string) {
+			if value == "" {
+				value = "-"
+			}
+			sb.WriteString(listItemStyle.Render(fmt.Sprintf("%-14s %s", name+":", value)))
+			sb.WriteString("\n")
+		}
+
+		row("Remote address", d.RemoteAddr)
+		row("Status", d.Status)
+		row("Duration", d.Duration.Round(time.Millisecond).String())
+		if d.TLSVersion == "" {
+			row("TLS", "not used")
+		} else {
+			row("TLS", d.TLSVersion)
+			row("Cipher suite", d.CipherSuite)
+			row("Certificate", d.CertSubject)
+			row("Expires", fmt.Sprintf("%s (in %s)",
+				d.CertExpiry.Format(time.RFC3339),
+				time.Until(d.CertExpiry).Round(time.Hour)))
+		}
+
+		sb.WriteString("\n")
+		sb.WriteString(titleStyle.Render("Response headers"))
+		sb.WriteString("\n")
+
+		names := make([]string, 0, len(d.Header))
+		for name := range d.Header {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			sb.WriteString(listItemStyle.Render(fmt.Sprintf("%s: %s", name, strings.Join(d.Header[name], ", "))))
+			sb.WriteString("\n")
+		}
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(helpStyle.Render("Press D, Esc or q to close"))
+	return sb.String()
+}
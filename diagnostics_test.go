@@ -0,0 +1,34 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchRecordsDiagnostics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Served-By", "node-1")
+		w.Write([]byte("up 1\n"))
+	}))
+	defer server.Close()
+
+	diagnostics := &connDiagnostics{}
+	if _, err := fetchAllMetricSeries(fetchConfig{diagnostics: diagnostics}, server.URL, "up", matchExact); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diagnostics.RemoteAddr != strings.TrimPrefix(server.URL, "http://") {
+		t.Fatalf("expected remote address %s, got %s", server.URL, diagnostics.RemoteAddr)
+	}
+	if diagnostics.Status != "200 OK" {
+		t.Fatalf("expected status 200 OK, got %s", diagnostics.Status)
+	}
+	if got := diagnostics.Header.Get("X-Served-By"); got != "node-1" {
+		t.Fatalf("expected response header to be recorded, got %q", got)
+	}
+	if diagnostics.TLSVersion != "" {
+		t.Fatalf("expected no TLS details for plain HTTP, got %s", diagnostics.TLSVersion)
+	}
+}
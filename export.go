@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NimbleMarkets/ntcharts/linechart/timeserieslinechart"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// CSVExportMsg reports the outcome of a CSV export
+type CSVExportMsg struct {
+	Path string
+	Err  error
+}
+
+// csvRow is a single sample of a CSV export
+type csvRow struct {
+	name  string
+	point timeserieslinechart.TimePoint
+}
+
+// writeCSV writes the points of the named series ordered by time, keeping the
+// order of the names for samples of the same scrape
+func writeCSV(w io.Writer, history map[string][]timeserieslinechart.TimePoint, names []string) error {
+	var rows []csvRow
+	for _, name := range names {
+		for _, point := range history[name] {
+			rows = append(rows, csvRow{name: name, point: point})
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].point.Time.Before(rows[j].point.Time) })
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"time", "series", "value"}); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := []string{
+			row.point.Time.Format(time.RFC3339Nano),
+			row.name,
+			strconv.FormatFloat(row.point.Value, 'g', -1, 64),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// exportFileName returns the name of an export file of a metric. Characters that
+// aren't safe in file names, like the / of a metric pattern, are replaced with _.
+func exportFileName(metricName, ext string) string {
+	safe := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '.' || r == '-' {
+			return r
+		}
+		return '_'
+	}, metricName)
+	return fmt.Sprintf("slashmetrics-%s-%s.%s", safe, time.Now().Format("20060102-150405"), ext)
+}
+
+// exportCSVCmd returns a command that writes the named series to a timestamped CSV file in dir
+func exportCSVCmd(history map[string][]timeserieslinechart.TimePoint, names []string, metricName, dir string) tea.Cmd {
+	return func() tea.Msg {
+		path := filepath.Join(dir, exportFileName(metricName, "csv"))
+		f, err := os.Create(path)
+		if err != nil {
+			return CSVExportMsg{Path: path, Err: fmt.Errorf("failed to export CSV: %w", err)}
+		}
+		if err := writeCSV(f, history, names); err != nil {
+			f.Close()
+			return CSVExportMsg{Path: path, Err: fmt.Errorf("failed to export CSV: %w", err)}
+		}
+		if err := f.Close(); err != nil {
+			return CSVExportMsg{Path: path, Err: fmt.Errorf("failed to export CSV: %w", err)}
+		}
+		return CSVExportMsg{Path: path}
+	}
+}
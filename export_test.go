@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NimbleMarkets/ntcharts/linechart/timeserieslinechart"
+)
+
+func TestWriteCSV(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	history := map[string][]timeserieslinechart.TimePoint{
+		`up{instance="a"}`: {{Time: start, Value: 1}, {Time: start.Add(time.Second), Value: 0.5}},
+		`up{instance="b"}`: {{Time: start, Value: 2}},
+		`up{instance="c"}`: {{Time: start, Value: 3}},
+	}
+
+	var sb strings.Builder
+	if err := writeCSV(&sb, history, []string{`up{instance="a"}`, `up{instance="b"}`}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "time,series,value\n" +
+		"2024-01-02T03:04:05Z,\"up{instance=\"\"a\"\"}\",1\n" +
+		"2024-01-02T03:04:05Z,\"up{instance=\"\"b\"\"}\",2\n" +
+		"2024-01-02T03:04:06Z,\"up{instance=\"\"a\"\"}\",0.5\n"
+	if sb.String() != want {
+		t.Fatalf("expected\n%s\ngot\n%s", want, sb.String())
+	}
+}
+
+func TestExportCSVCmd(t *testing.T) {
+	dir := t.TempDir()
+	msg := exportCSVCmd(nil, nil, "up", dir)().(CSVExportMsg)
+	if msg.Err != nil {
+		t.Fatalf("unexpected error: %v", msg.Err)
+	}
+	if filepath.Dir(msg.Path) != dir {
+		t.Fatalf("expected the export in %s, got %s", dir, msg.Path)
+	}
+	if _, err := os.Stat(msg.Path); err != nil {
+		t.Fatalf("expected the file to exist: %v", err)
+	}
+
+	// Metric lists and patterns don't leave the export directory
+	msg = exportCSVCmd(nil, nil, "../up,node_.*/x", dir)().(CSVExportMsg)
+	if msg.Err != nil || filepath.Dir(msg.Path) != dir {
+		t.Fatalf("expected the export in %s, got %s (%v)", dir, msg.Path, msg.Err)
+	}
+	if name := filepath.Base(msg.Path); !strings.HasPrefix(name, "slashmetrics-.._up_node_.__x-") {
+		t.Fatalf("expected unsafe characters to be replaced, got %s", name)
+	}
+
+	msg = exportCSVCmd(nil, nil, "up", filepath.Join(dir, "missing"))().(CSVExportMsg)
+	if msg.Err == nil {
+		t.Fatal("expected an error for a missing directory")
+	}
+}
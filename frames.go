@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// scrapeTimes returns the distinct times of all captured scrapes in order
+func (m *Model) scrapeTimes() []time.Time {
+	seen := make(map[int64]bool)
+	var times []time.Time
+	for name := range m.dataHistory {
+		for _, point := range m.windowPoints(name) {
+			if !seen[point.Time.UnixNano()] {
+				seen[point.Time.UnixNano()] = true
+				times = append(times, point.Time)
+			}
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	return times
+}
+
+// stepFrame moves the frame cursor by delta scrapes. Stepping back from the
+// live view starts at the scrape before the latest one, stepping past the
+// latest scrape returns to the live view.
+func (m *Model) stepFrame(delta int) {
+	times := m.scrapeTimes()
+	if len(times) == 0 {
+		return
+	}
+
+	idx := len(times) - 1
+	if !m.frameCursor.IsZero() {
+		idx = sort.Search(len(times), func(i int) bool { return !times[i].Before(m.frameCursor) })
+	}
+
+	idx += delta
+	switch {
+	case idx >= len(times)-1:
+		m.frameCursor = time.Time{}
+	case idx < 0:
+		m.frameCursor = times[0]
+	default:
+		m.frameCursor = times[idx]
+	}
+}
+
+// currentValue returns the value of a series as of the frame cursor, or its latest value when live
+func (m *Model) currentValue(name string) (float64, bool) {
+	if m.frameCursor.IsZero() {
+		value, ok := m.lastValues[name]
+		return value, ok
+	}
+
+	points := m.dataHistory[name]
+	for i := len(points) - 1; i >= 0; i-- {
+		if !points[i].Time.After(m.frameCursor) {
+			return points[i].Value, true
+		}
+	}
+	return 0, false
+}
+
+// stale reports whether the last scrapes failed while the chart still shows earlier data
+func (m *Model) stale() bool {
+	return m.emptyScrapes > 0 && m.err == nil
+}
+
+// staleColor marks the age of the last scrape once it is overdue
+var staleColor = lipgloss.Color("196")
+
+// updatedText tells how long ago the last successful scrape was and whether
+// three intervals passed without one. Replays have no such scrape, and no scrape
+// is due while paused.
+func (m *Model) updatedText(now time.Time) (string, bool) {
+	if m.lastUpdate.IsZero() || m.replayFrames != nil {
+		return "", false
+	}
+	age := now.Sub(m.lastUpdate)
+	return fmt.Sprintf("Updated %s ago", age.Round(time.Second)), !m.paused && age > 3*m.interval
+}
+
+// following reports whether the chart shows the full, live time range
+func (m *Model) following() bool {
+	return m.frameCursor.IsZero() && m.chart.ViewMinX() <= m.chart.MinX() && m.chart.ViewMaxX() >= m.chart.MaxX()
+}
+
+// followLive returns to the live view of the full time range, which new scrapes
+// extend again. Data, Y range and series selection are left alone, resuming a
+// pause is up to the caller.
+func (m *Model) followLive() {
+	m.frameCursor = time.Time{}
+	m.chart.SetViewTimeRange(time.Unix(int64(m.chart.MinX()), 0), time.Unix(int64(math.Ceil(m.chart.MaxX())), 0))
+}
+
+// resume continues scraping after a pause, starting with an immediate scrape
+func (m *Model) resume() tea.Cmd {
+	if !m.paused {
+		return nil
+	}
+	m.paused = false
+	return m.fetchOnce()
+}
+
+// fetchOnce scrapes a single time, e.g. to refresh the chart while paused
+func (m *Model) fetchOnce() tea.Cmd {
+	// Replays are loaded at once and have nothing to scrape
+	if m.replayFrames != nil {
+		return nil
+	}
+	return m.startFetch(fetchMetricCmd(m.fetch, m.urls, m.metricName, m.metricMatch))
+}
+
+// minZoomSpan is the shortest time span in seconds the time axis can be zoomed to
+const minZoomSpan = 5
+
+// zoomTime scales the viewed time span by factor, keeping its end in place so
+// zooming in focuses on the most recent data of the view. The span is clamped
+// to the captured time range.
+func (m *Model) zoomTime(factor float64) {
+	minX, maxX := m.chart.MinX(), m.chart.MaxX()
+	viewMax := m.chart.ViewMaxX()
+	span := (viewMax - m.chart.ViewMinX()) * factor
+	span = min(max(span, minZoomSpan), maxX-minX)
+
+	viewMin := viewMax - span
+	if viewMin < minX {
+		viewMin = minX
+		viewMax = min(minX+span, maxX)
+	}
+	m.chart.SetViewTimeRange(time.Unix(int64(viewMin), 0), time.Unix(int64(math.Ceil(viewMax)), 0))
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NimbleMarkets/ntcharts/linechart/timeserieslinechart"
+	tea "github.com/charmbracelet/bubbletea"
+	zone "github.com/lrstanley/bubblezone"
+)
+
+func TestStepFrame(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	at := func(i int, v float64) timeserieslinechart.TimePoint {
+		return timeserieslinechart.TimePoint{Time: start.Add(time.Duration(i) * time.Second), Value: v}
+	}
+
+	m := NewModel("http://localhost", "metric", time.Second)
+	m.dataHistory = map[string][]timeserieslinechart.TimePoint{
+		"a": {at(0, 1), at(1, 2), at(2, 3)},
+		"b": {at(1, 10), at(2, 20)},
+	}
+	m.lastValues = map[string]float64{"a": 3, "b": 20}
+
+	m.stepFrame(-1)
+	if !m.frameCursor.Equal(start.Add(time.Second)) {
+		t.Fatalf("expected to step back to the second scrape, got %v", m.frameCursor)
+	}
+	if v, ok := m.currentValue("a"); !ok || v != 2 {
+		t.Fatalf("expected the value as of the frame, got %v (%v)", v, ok)
+	}
+
+	m.stepFrame(-5)
+	if !m.frameCursor.Equal(start) {
+		t.Fatalf("expected to stop at the first scrape, got %v", m.frameCursor)
+	}
+	if _, ok := m.currentValue("b"); ok {
+		t.Fatal("expected no value before the series' first scrape")
+	}
+
+	m.stepFrame(2)
+	if !m.frameCursor.IsZero() {
+		t.Fatalf("expected to return to the live view, got %v", m.frameCursor)
+	}
+	if v, _ := m.currentValue("a"); v != 3 {
+		t.Fatalf("expected the latest value when live, got %v", v)
+	}
+}
+
+func TestFollowLive(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	m := NewModel("http://localhost", "metric", time.Second)
+	m.chart.SetTimeRange(start, start.Add(time.Minute))
+	m.chart.SetViewTimeRange(start, start.Add(time.Minute))
+	if !m.following() {
+		t.Fatal("expected the full range to be followed")
+	}
+
+	m.chart.SetViewTimeRange(start.Add(10*time.Second), start.Add(20*time.Second))
+	m.frameCursor = start.Add(15 * time.Second)
+	if m.following() {
+		t.Fatal("expected a narrowed view not to be followed")
+	}
+
+	m.followLive()
+	if !m.following() || !m.frameCursor.IsZero() {
+		t.Fatalf("expected to follow the live view again, got view %v-%v and frame %v",
+			m.chart.ViewMinX(), m.chart.ViewMaxX(), m.frameCursor)
+	}
+}
+
+func TestZoomTime(t *testing.T) {
+	zone.NewGlobal()
+	start := time.Unix(1700000000, 0)
+	m := NewModel("http://localhost", "up", time.Second)
+	scrape := func(i int) {
+		model, _ := m.Update(MetricsMsg{
+			Samples: []MetricSample{{FullName: "up", Value: float64(i)}},
+			Time:    start.Add(time.Duration(i) * time.Second),
+		})
+		m = model.(Model)
+	}
+	for i := range 61 {
+		scrape(i)
+	}
+
+	m.zoomTime(0.5)
+	if m.chart.ViewMinX() != float64(start.Unix()+30) || m.chart.ViewMaxX() != float64(start.Unix()+60) {
+		t.Fatalf("expected the latest half of the range, got %v-%v", m.chart.ViewMinX(), m.chart.ViewMaxX())
+	}
+
+	// New scrapes extend the range but leave the zoomed view in place
+	scrape(61)
+	if m.chart.ViewMinX() != float64(start.Unix()+30) || m.chart.ViewMaxX() != float64(start.Unix()+60) {
+		t.Fatalf("expected the zoomed view to stay put, got %v-%v", m.chart.ViewMinX(), m.chart.ViewMaxX())
+	}
+
+	for range 10 {
+		m.zoomTime(0.5)
+	}
+	if span := m.chart.ViewMaxX() - m.chart.ViewMinX(); span != minZoomSpan {
+		t.Fatalf("expected the span to stop at %d seconds, got %v", minZoomSpan, span)
+	}
+
+	for range 10 {
+		m.zoomTime(2)
+	}
+	if m.chart.ViewMinX() != m.chart.MinX() {
+		t.Fatalf("expected zooming out to stop at the captured range, got %v", m.chart.ViewMinX())
+	}
+
+	m.followLive()
+	scrape(62)
+	if !m.following() || m.chart.ViewMaxX() < float64(start.Unix()+62) {
+		t.Fatalf("expected to follow new scrapes again, got view %v-%v", m.chart.ViewMinX(), m.chart.ViewMaxX())
+	}
+}
+
+func TestUpdatedText(t *testing.T) {
+	m := NewModel("http://localhost", "up", 2*time.Second)
+	now := time.Now()
+	if text, _ := m.updatedText(now); text != "" {
+		t.Fatalf("expected nothing before the first scrape, got %q", text)
+	}
+
+	m.lastUpdate = now.Add(-4 * time.Second)
+	if text, overdue := m.updatedText(now); text != "Updated 4s ago" || overdue {
+		t.Fatalf("expected a recent update, got %q (overdue %v)", text, overdue)
+	}
+
+	m.lastUpdate = now.Add(-7 * time.Second)
+	if text, overdue := m.updatedText(now); text != "Updated 7s ago" || !overdue {
+		t.Fatalf("expected an overdue update after three intervals, got %q (overdue %v)", text, overdue)
+	}
+}
+
+func TestUpdatedTextWhilePaused(t *testing.T) {
+	zone.NewGlobal()
+	m := NewModel("http://localhost", "up", 2*time.Second)
+	now := time.Now()
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	model, _ = model.Update(MetricsMsg{
+		Samples: []MetricSample{{FullName: "up{}", Value: 1}},
+		Time:    now.Add(-7 * time.Second),
+	})
+	m = model.(Model)
+
+	if text, overdue := m.updatedText(now); text != "Updated 7s ago" || overdue {
+		t.Fatalf("expected no overdue update while paused, got %q (overdue %v)", text, overdue)
+	}
+	if view := m.View(); !strings.Contains(view, "Updated 7s ago") {
+		t.Fatalf("expected the age of the last scrape in the header, got\n%s", view)
+	}
+}
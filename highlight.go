@@ -0,0 +1,34 @@
+package main
+
+import "github.com/NimbleMarkets/ntcharts/canvas"
+
+// heavyLines maps the thin and arc line runes to their heavy counterparts
+var heavyLines = map[rune]rune{
+	'─': '━', '│': '┃',
+	'┌': '┏', '╭': '┏', '┐': '┓', '╮': '┓',
+	'└': '┗', '╰': '┗', '┘': '┛', '╯': '┛',
+	'├': '┣', '┤': '┫', '┬': '┳', '┴': '┻', '┼': '╋',
+	'╴': '╸', '╵': '╹', '╶': '╺', '╷': '╻',
+}
+
+// thickenHighlighted redraws the lines of series matching --highlight with heavy
+// runes. ntcharts only draws thin lines, but only emphasized lines are bold.
+func (m *Model) thickenHighlighted() {
+	if m.highlight == nil {
+		return
+	}
+
+	origin := m.chart.Origin()
+	for y := 0; y < origin.Y; y++ {
+		for x := origin.X + 1; x < m.chart.Width(); x++ {
+			p := canvas.Point{X: x, Y: y}
+			cell := m.chart.Canvas.Cell(p)
+			heavy, ok := heavyLines[cell.Rune]
+			if !ok || !cell.Style.GetBold() {
+				continue
+			}
+			cell.Rune = heavy
+			m.chart.Canvas.SetCell(p, cell)
+		}
+	}
+}
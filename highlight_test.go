@@ -0,0 +1,42 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/NimbleMarkets/ntcharts/canvas"
+	zone "github.com/lrstanley/bubblezone"
+)
+
+func TestThickenHighlighted(t *testing.T) {
+	zone.NewGlobal()
+	start := time.Unix(1700000000, 0)
+	m := NewModel("http://localhost", "up", time.Second)
+	m.highlight = regexp.MustCompile(`instance="a"`)
+	for i := range 10 {
+		model, _ := m.Update(MetricsMsg{
+			Samples: []MetricSample{{FullName: `up{instance="a"}`, Value: 90}, {FullName: `up{instance="b"}`, Value: 30}},
+			Time:    start.Add(time.Duration(i) * time.Second),
+		})
+		m = model.(Model)
+	}
+
+	var heavy, thin bool
+	for y := 0; y < m.chart.Origin().Y; y++ {
+		for x := m.chart.Origin().X + 1; x < m.chart.Width(); x++ {
+			switch m.chart.Canvas.Cell(canvas.Point{X: x, Y: y}).Rune {
+			case '━':
+				heavy = true
+			case '─':
+				thin = true
+			}
+		}
+	}
+	if !heavy {
+		t.Fatal("expected the highlighted series to be drawn with heavy lines")
+	}
+	if !thin {
+		t.Fatal("expected other series to be drawn with thin lines")
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// histogramBucket counts the values within [low, high)
+type histogramBucket struct {
+	low, high float64
+	count     int
+}
+
+// histogramBuckets splits the range of values into n equally wide buckets,
+// picking n by Sturges' rule if it is 0. The last bucket includes the maximum.
+func histogramBuckets(values []float64, n int) []histogramBucket {
+	if len(values) == 0 {
+		return nil
+	}
+
+	minVal, maxVal := values[0], values[0]
+	for _, v := range values {
+		minVal = math.Min(minVal, v)
+		maxVal = math.Max(maxVal, v)
+	}
+	if minVal == maxVal {
+		return []histogramBucket{{low: minVal, high: maxVal, count: len(values)}}
+	}
+
+	if n <= 0 {
+		n = int(math.Ceil(math.Log2(float64(len(values))))) + 1
+	}
+
+	width := (maxVal - minVal) / float64(n)
+	buckets := make([]histogramBucket, n)
+	for i := range buckets {
+		buckets[i].low = minVal + float64(i)*width
+		buckets[i].high = minVal + float64(i+1)*width
+	}
+	for _, v := range values {
+		i := min(int((v-minVal)/width), n-1)
+		buckets[i].count++
+	}
+	return buckets
+}
+
+// histogramView renders the distribution of the values of a series
+func (m *Model) histogramView() string {
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render("Distribution of " + seriesDisplayName(m.histogramSeries)))
+	sb.WriteString("\n\n")
+
+	var values []float64
+	for _, point := range m.windowPoints(m.histogramSeries) {
+		values = append(values, point.Value)
+	}
+
+	buckets := histogramBuckets(values, m.histogramBuckets)
+	if len(buckets) == 0 {
+		sb.WriteString(listItemStyle.Render("No values captured yet"))
+		sb.WriteString("\n")
+	}
+
+	maxCount := 0
+	for _, b := range buckets {
+		maxCount = max(maxCount, b.count)
+	}
+	barWidth := max(m.termWidth-50, 10)
+	yLabel := m.yFormatter()
+	barStyle := lipgloss.NewStyle().Foreground(accentColor)
+
+	// Largest values on top, like the Y axis of the chart
+	for i := len(buckets) - 1; i >= 0; i-- {
+		b := buckets[i]
+		label := fmt.Sprintf("%10s – %-10s", yLabel(0, b.low), yLabel(0, b.high))
+		bar := strings.Repeat("█", b.count*barWidth/maxCount)
+		sb.WriteString(listItemStyle.Render(fmt.Sprintf("%s │%s %d", label, barStyle.Render(bar), b.count)))
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(helpStyle.Render("Press h, Esc or q to close"))
+	return sb.String()
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHistogramBuckets(t *testing.T) {
+	got := histogramBuckets([]float64{0, 1, 2, 3, 4, 9, 10}, 2)
+	want := []histogramBucket{
+		{low: 0, high: 5, count: 5},
+		{low: 5, high: 10, count: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	// Sturges' rule picks ceil(log2(8)) + 1 = 4 buckets
+	if got := histogramBuckets([]float64{1, 2, 3, 4, 5, 6, 7, 8}, 0); len(got) != 4 {
+		t.Fatalf("expected 4 automatic buckets, got %d", len(got))
+	}
+
+	if got := histogramBuckets([]float64{3, 3, 3}, 5); len(got) != 1 || got[0].count != 3 {
+		t.Fatalf("expected a single bucket for constant values, got %v", got)
+	}
+
+	if got := histogramBuckets(nil, 5); got != nil {
+		t.Fatalf("expected no buckets without values, got %v", got)
+	}
+}
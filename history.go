@@ -0,0 +1,47 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/NimbleMarkets/ntcharts/linechart/timeserieslinechart"
+)
+
+// appendHistory appends a point and drops the oldest one once max points are
+// kept (0 for unlimited). Returns whether a point was dropped.
+func appendHistory(points []timeserieslinechart.TimePoint, point timeserieslinechart.TimePoint, max int) ([]timeserieslinechart.TimePoint, bool) {
+	if max <= 0 || len(points) < max {
+		return append(points, point), false
+	}
+	// Slicing off the head lets append move the kept points to a new array once
+	// the capacity runs out, so the dropped ones can be garbage collected
+	return append(points[len(points)-max+1:], point), true
+}
+
+// evictHistory moves the start of the time axis to the oldest point left after
+// a scrape dropped points. The dropped points lie before the axis and aren't drawn,
+// but the chart still holds them until it's rebuilt. Returns whether enough scrapes
+// dropped points since the last rebuild that the chart should be rebuilt now.
+func (m *Model) evictHistory() bool {
+	var oldest time.Time
+	for _, points := range m.dataHistory {
+		if len(points) > 0 && (oldest.IsZero() || points[0].Time.Before(oldest)) {
+			oldest = points[0].Time
+		}
+	}
+	if oldest.IsZero() {
+		return false
+	}
+
+	// Keep the viewed range where it is unless it started before the oldest point
+	viewMin, viewMax := m.chart.ViewMinX(), m.chart.ViewMaxX()
+	start := float64(oldest.Unix())
+	if viewMin <= m.chart.MinX() || viewMin < start {
+		viewMin = start
+	}
+	m.chart.SetTimeRange(oldest, time.Unix(int64(math.Ceil(m.chart.MaxX())), 0))
+	m.chart.SetViewTimeRange(time.Unix(int64(viewMin), 0), time.Unix(int64(math.Ceil(viewMax)), 0))
+
+	m.evictedScrapes++
+	return m.evictedScrapes >= m.maxPoints
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	zone "github.com/lrstanley/bubblezone"
+)
+
+func TestAppendHistory(t *testing.T) {
+	points := trendPoints(1, 2, 3)
+	next := trendPoints(1, 2, 3, 4)[3]
+
+	got, dropped := appendHistory(points, next, 3)
+	if !dropped || len(got) != 3 || got[0].Value != 2 || got[2].Value != 4 {
+		t.Fatalf("expected the newest 3 points, got %v (dropped %v)", got, dropped)
+	}
+	if got, dropped := appendHistory(points, next, 0); dropped || len(got) != 4 {
+		t.Fatalf("expected no cap for 0, got %d points", len(got))
+	}
+	if got, dropped := appendHistory(points, next, 10); dropped || len(got) != 4 {
+		t.Fatalf("expected all points below the cap, got %d points", len(got))
+	}
+}
+
+func TestEvictHistoryMovesTimeAxis(t *testing.T) {
+	zone.NewGlobal()
+	m := NewModel("http://localhost", "up", time.Second)
+	m.maxPoints = 3
+	start := time.Unix(1000, 0)
+
+	for i := range 5 {
+		model, _ := m.Update(MetricsMsg{
+			Samples: []MetricSample{{FullName: `up{instance="a"}`, Value: float64(i)}},
+			Time:    start.Add(time.Duration(i) * time.Second),
+		})
+		m = model.(Model)
+	}
+
+	points := m.dataHistory[`up{instance="a"}`]
+	if len(points) != 3 || points[0].Value != 2 {
+		t.Fatalf("expected the newest 3 points, got %v", points)
+	}
+	if got, want := m.chart.MinX(), float64(start.Add(2*time.Second).Unix()); got != want {
+		t.Fatalf("expected the time axis to start at %v, got %v", want, got)
+	}
+	if m.chart.ViewMinX() != m.chart.MinX() {
+		t.Fatalf("expected the view to follow the time axis, got %v", m.chart.ViewMinX())
+	}
+
+	if m.evictedScrapes != 2 {
+		t.Fatalf("expected the chart to be kept for 2 evicting scrapes, got %d", m.evictedScrapes)
+	}
+
+	// The chart is rebuilt once as many scrapes dropped points as are kept
+	model, _ := m.Update(MetricsMsg{
+		Samples: []MetricSample{{FullName: `up{instance="a"}`, Value: 5}},
+		Time:    start.Add(5 * time.Second),
+	})
+	m = model.(Model)
+	if m.evictedScrapes != 0 {
+		t.Fatalf("expected the chart to be rebuilt, got %d evicting scrapes since", m.evictedScrapes)
+	}
+	if got, want := m.chart.MinX(), float64(start.Add(3*time.Second).Unix()); got != want {
+		t.Fatalf("expected the time axis to start at %v, got %v", want, got)
+	}
+}
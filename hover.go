@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/NimbleMarkets/ntcharts/linechart/timeserieslinechart"
+)
+
+// hoverPoint is the data point nearest to the mouse cursor on the chart
+type hoverPoint struct {
+	name  string
+	point timeserieslinechart.TimePoint
+}
+
+// chartCoords maps a cell of the chart to a time (in seconds) and a value on
+// the chart's scale. Cells outside of the graphing area don't map to anything.
+func (m *Model) chartCoords(x, y int) (float64, float64, bool) {
+	origin := m.chart.Origin()
+	col, row := x-origin.X, origin.Y-y
+	width, height := m.chart.GraphWidth(), m.chart.GraphHeight()
+	if col < 0 || col > width || row < 0 || row > height || width == 0 || height == 0 {
+		return 0, 0, false
+	}
+
+	t := m.chart.ViewMinX() + float64(col)/float64(width)*(m.chart.ViewMaxX()-m.chart.ViewMinX())
+	v := m.chart.ViewMinY() + float64(row)/float64(height)*(m.chart.ViewMaxY()-m.chart.ViewMinY())
+	return t, v, true
+}
+
+// nearestPoint returns the point of a series closest in time to t
+func nearestPoint(points []timeserieslinechart.TimePoint, t float64) (timeserieslinechart.TimePoint, bool) {
+	if len(points) == 0 {
+		return timeserieslinechart.TimePoint{}, false
+	}
+	seconds := func(p timeserieslinechart.TimePoint) float64 { return float64(p.Time.UnixNano()) / 1e9 }
+
+	i := sort.Search(len(points), func(i int) bool { return seconds(points[i]) >= t })
+	switch {
+	case i == len(points):
+		return points[i-1], true
+	case i > 0 && t-seconds(points[i-1]) < seconds(points[i])-t:
+		return points[i-1], true
+	default:
+		return points[i], true
+	}
+}
+
+// hoverAt finds the plotted point under the cell of the chart: the point closest
+// in time of the series whose value there is closest to the cursor
+func (m *Model) hoverAt(x, y int) (hoverPoint, bool) {
+	t, v, ok := m.chartCoords(x, y)
+	if !ok {
+		return hoverPoint{}, false
+	}
+
+	var best hoverPoint
+	found := false
+	bestDist := math.Inf(1)
+	for _, line := range m.plotLines() {
+		point, ok := nearestPoint(line.points, t)
+		if !ok || float64(point.Time.Unix()) < m.chart.ViewMinX() || float64(point.Time.Unix()) > m.chart.ViewMaxX() {
+			continue
+		}
+		cv, ok := m.chartValue(point.Value)
+		if !ok {
+			continue
+		}
+		if dist := math.Abs(cv - v); dist < bestDist {
+			best, bestDist, found = hoverPoint{name: line.name, point: point}, dist, true
+		}
+	}
+	return best, found
+}
+
+// hoverText renders the readout of the hovered point for the help bar
+func (m *Model) hoverText() string {
+	if m.hover == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s @ %s = %s", seriesDisplayName(m.hover.name),
+		formatPointTime(m.hover.point.Time), strconv.FormatFloat(m.hover.point.Value, 'g', -1, 64))
+}
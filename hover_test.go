@@ -0,0 +1,66 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	zone "github.com/lrstanley/bubblezone"
+)
+
+func TestNearestPoint(t *testing.T) {
+	points := trendPoints(1, 2, 3)
+	at := func(i int) float64 { return float64(points[i].Time.Unix()) }
+
+	tests := []struct {
+		t    float64
+		want float64
+	}{
+		{at(0) - 10, 1},
+		{at(1) - 0.4, 2},
+		{at(1) + 0.6, 3},
+		{at(2) + 10, 3},
+	}
+	for _, tt := range tests {
+		if p, ok := nearestPoint(points, tt.t); !ok || p.Value != tt.want {
+			t.Errorf("nearestPoint(%v) = %v, want %v", tt.t, p.Value, tt.want)
+		}
+	}
+	if _, ok := nearestPoint(nil, 0); ok {
+		t.Error("expected no point of an empty series")
+	}
+}
+
+func TestHoverAt(t *testing.T) {
+	zone.NewGlobal()
+	start := time.Unix(1700000000, 0)
+	m := NewModel("http://localhost", "up", time.Second)
+	for i := range 10 {
+		model, _ := m.Update(MetricsMsg{
+			Samples: []MetricSample{{FullName: `up{instance="a"}`, Value: 1}, {FullName: `up{instance="b"}`, Value: 100}},
+			Time:    start.Add(time.Duration(i) * time.Second),
+		})
+		m = model.(Model)
+	}
+
+	origin := m.chart.Origin()
+	right := origin.X + m.chart.GraphWidth()
+
+	hover, ok := m.hoverAt(right, origin.Y-m.chart.GraphHeight())
+	if !ok || hover.name != `up{instance="b"}` || !hover.point.Time.Equal(start.Add(9*time.Second)) {
+		t.Fatalf("expected the latest point of the upper series, got %+v", hover)
+	}
+
+	hover, ok = m.hoverAt(origin.X, origin.Y)
+	if !ok || hover.name != `up{instance="a"}` || !hover.point.Time.Equal(start) {
+		t.Fatalf("expected the first point of the lower series, got %+v", hover)
+	}
+
+	if _, ok := m.hoverAt(origin.X-1, origin.Y); ok {
+		t.Fatal("expected nothing to hover left of the graph")
+	}
+
+	m.hover = &hover
+	if got := m.hoverText(); got != `up{instance="a"} @ `+formatPointTime(start)+" = 1" {
+		t.Fatalf("unexpected readout %q", got)
+	}
+}
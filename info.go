@@ -0,0 +1,96 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/NimbleMarkets/ntcharts/linechart/timeserieslinechart"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// isInfoMetric reports whether a metric only carries information in its labels,
+// either declared by its TYPE or named *_info with all values being 1
+func isInfoMetric(name string, meta MetricMeta, samples []MetricSample) bool {
+	if meta.Type == "info" {
+		return true
+	}
+	if !strings.HasSuffix(name, "_info") || len(samples) == 0 {
+		return false
+	}
+	for _, sample := range samples {
+		if sample.Value != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// isInfo reports whether the samples only belong to info metrics, judged by the
+// TYPE of each metric or its samples. Without samples the metrics are judged by TYPE.
+func (m *Model) isInfo(samples []MetricSample) bool {
+	byMetric := make(map[string][]MetricSample)
+	for _, sample := range samples {
+		base, _, _ := strings.Cut(sample.FullName, "{")
+		byMetric[base] = append(byMetric[base], sample)
+	}
+	if len(byMetric) == 0 {
+		for _, name := range metricNames(m.metricName) {
+			byMetric[name] = nil
+		}
+	}
+
+	for name, samples := range byMetric {
+		meta, _ := lookupMeta(m.metadata, name)
+		if !isInfoMetric(name, meta, samples) {
+			return false
+		}
+	}
+	return len(byMetric) > 0
+}
+
+// latestSamples returns the latest values of the listed series as samples
+func (m *Model) latestSamples() []MetricSample {
+	samples := make([]MetricSample, 0, len(m.seriesList))
+	for _, series := range m.seriesList {
+		if value, ok := m.lastValues[series.name]; ok {
+			_, labels := parseLabels(series.name)
+			samples = append(samples, MetricSample{FullName: series.name, Labels: labels, Value: value})
+		}
+	}
+	return samples
+}
+
+// listAsInfo moves the plotted series to the info panel, for info metrics only
+// declared as such by their TYPE, which may arrive after the samples
+func (m *Model) listAsInfo(samples []MetricSample) {
+	m.infoSamples = samples
+	m.seriesList = nil
+	m.lastValues = make(map[string]float64)
+	m.dataHistory = make(map[string][]timeserieslinechart.TimePoint)
+	m.redrawChart()
+}
+
+// infoPanelView lists the label sets of an info metric in place of the chart
+func (m *Model) infoPanelView() string {
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render("Info"))
+	sb.WriteString("\n\n")
+
+	for _, sample := range m.infoSamples {
+		labels := sample.FullName
+		if idx := strings.Index(labels, "{"); idx != -1 {
+			labels = labels[idx:]
+		}
+		sb.WriteString(listItemStyle.Render(labels))
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(helpStyle.Render("Info metrics aren't charted, use --include-info to plot them"))
+
+	return lipgloss.NewStyle().
+		Width(m.width).
+		Height(m.height).
+		MaxWidth(m.width).
+		MaxHeight(m.height).
+		Render(sb.String())
+}
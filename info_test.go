@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	zone "github.com/lrstanley/bubblezone"
+)
+
+func TestIsInfoMetric(t *testing.T) {
+	tests := []struct {
+		name    string
+		metric  string
+		meta    MetricMeta
+		samples []MetricSample
+		want    bool
+	}{
+		{"info suffix", "build_info", MetricMeta{}, []MetricSample{{FullName: `build_info{version="1.0"}`, Value: 1}}, true},
+		{"info type", "target", MetricMeta{Type: "info"}, nil, true},
+		{"info suffix with other value", "build_info", MetricMeta{}, []MetricSample{{FullName: `build_info{}`, Value: 2}}, false},
+		{"info suffix without samples", "build_info", MetricMeta{}, nil, false},
+		{"regular metric", "up", MetricMeta{Type: "gauge"}, []MetricSample{{FullName: `up{}`, Value: 1}}, false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isInfoMetric(tt.metric, tt.meta, tt.samples); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestInfoTypeAfterSamples(t *testing.T) {
+	zone.NewGlobal()
+	m := NewModel("http://localhost", "target", time.Second)
+	model, _ := m.Update(MetricsMsg{Samples: []MetricSample{{FullName: `target{env="prod"}`, Value: 1}}})
+	model, _ = model.Update(MetadataMsg{Metadata: map[string]MetricMeta{"target": {Type: "info"}}})
+	m = model.(Model)
+
+	if len(m.infoSamples) != 1 || m.infoSamples[0].FullName != `target{env="prod"}` {
+		t.Fatalf("expected the plotted series to be listed, got %v", m.infoSamples)
+	}
+	if len(m.seriesList) != 0 || len(m.dataHistory) != 0 {
+		t.Fatalf("expected the info metric not to be charted, got %v", m.seriesList)
+	}
+}
+
+func TestIsInfoOfOverlaidMetrics(t *testing.T) {
+	m := NewModel("http://localhost", "target,build", time.Second)
+	m.metadata = map[string]MetricMeta{"target": {Type: "info"}, "build": {Type: "info"}, "up": {Type: "gauge"}}
+	if !m.isInfo(nil) {
+		t.Fatal("expected overlaid info metrics to be detected by TYPE")
+	}
+
+	samples := []MetricSample{{FullName: `target{env="prod"}`, Value: 1}, {FullName: `up{}`, Value: 1}}
+	if m.isInfo(samples) {
+		t.Fatal("expected a chart with a regular metric not to be listed as info")
+	}
+	if !m.isInfo(samples[:1]) {
+		t.Fatal("expected the info metric to be detected by its TYPE")
+	}
+}
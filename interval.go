@@ -0,0 +1,31 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Bounds of the scrape interval when changing it at runtime
+const (
+	minInterval = 250 * time.Millisecond
+	maxInterval = 10 * time.Minute
+)
+
+// scaleInterval multiplies the scrape interval by factor within its bounds. The
+// tick scheduled with the old interval is dropped in favor of a new one.
+func (m *Model) scaleInterval(factor float64) tea.Cmd {
+	// Replays are loaded at once and don't poll
+	if m.replayFrames != nil {
+		return nil
+	}
+
+	interval := time.Duration(float64(m.interval) * factor)
+	interval = min(max(interval, minInterval), maxInterval)
+	if interval == m.interval {
+		return nil
+	}
+	m.interval = interval
+	m.tickGen++
+	return tickCmd(m.interval, m.tickGen)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	zone "github.com/lrstanley/bubblezone"
+)
+
+func TestScaleInterval(t *testing.T) {
+	m := NewModel("http://localhost", "up", time.Second)
+
+	if cmd := m.scaleInterval(0.5); cmd == nil || m.interval != 500*time.Millisecond || m.tickGen != 1 {
+		t.Fatalf("expected a new tick at half the interval, got %v (generation %d)", m.interval, m.tickGen)
+	}
+	m.scaleInterval(0.5)
+	if cmd := m.scaleInterval(0.5); cmd != nil || m.interval != minInterval {
+		t.Fatalf("expected the interval to stop at %v, got %v", minInterval, m.interval)
+	}
+
+	m.interval = 8 * time.Minute
+	m.scaleInterval(2)
+	if m.interval != maxInterval {
+		t.Fatalf("expected the interval to stop at %v, got %v", maxInterval, m.interval)
+	}
+}
+
+func TestStaleTicksAreDropped(t *testing.T) {
+	zone.NewGlobal()
+	m := NewModel("http://localhost", "up", time.Second)
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(">")})
+	m = model.(Model)
+	if m.interval != 2*time.Second {
+		t.Fatalf("expected the interval to double, got %v", m.interval)
+	}
+
+	if _, cmd := m.Update(TickMsg{Time: time.Now(), Gen: 0}); cmd != nil {
+		t.Fatal("expected the tick of the old interval to be dropped")
+	}
+	if _, cmd := m.Update(TickMsg{Time: time.Now(), Gen: m.tickGen}); cmd == nil {
+		t.Fatal("expected the current tick to scrape and tick again")
+	}
+}
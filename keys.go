@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// keyMode is the UI mode a keybinding applies to
+type keyMode int
+
+const (
+	modeNormal keyMode = iota
+	modeSeriesSelect
+	modeMetricSelect
+)
+
+// keyModeTitles are the section titles of the help overlay
+var keyModeTitles = map[keyMode]string{
+	modeNormal:       "Chart",
+	modeSeriesSelect: "Series selection",
+	modeMetricSelect: "Metric selection",
+}
+
+// keyBinding describes a keybinding for the help bar and help overlay
+type keyBinding struct {
+	keys string              // Keys as shown to the user
+	desc string              // Short description of the action
+	mode keyMode             // Mode the binding is available in
+	bar  bool                // Whether the binding is shown in the help bar
+	when func(m *Model) bool // Optional condition for showing the binding in the help bar
+}
+
+// keyBindings lists all keybindings in the order they are shown.
+// Add new bindings here so they show up in the help bar and the help overlay.
+var keyBindings = []keyBinding{
+	{keys: "q", desc: "Quit", mode: modeNormal, bar: true},
+	{keys: "m", desc: "Metrics", mode: modeNormal, bar: true},
+	{keys: "/", desc: "Search series of all metrics", mode: modeNormal},
+	{keys: "s", desc: "Series", mode: modeNormal, bar: true},
+	{keys: "l", desc: "Legend", mode: modeNormal, bar: true},
+	{keys: "r", desc: "Reset", mode: modeNormal, bar: true},
+	{keys: "?", desc: "Help", mode: modeNormal, bar: true},
+	{keys: "b", desc: "Border", mode: modeNormal},
+	{keys: "z", desc: "Fullscreen chart", mode: modeNormal},
+	{keys: "w", desc: "Fill/cap chart width", mode: modeNormal},
+	{keys: "v", desc: "History overview", mode: modeNormal},
+	{keys: "t", desc: "Table of current values", mode: modeNormal},
+	{keys: "n/N", desc: "Solo next/previous series", mode: modeNormal},
+	{keys: "esc", desc: "Exit solo", mode: modeNormal, bar: true, when: func(m *Model) bool { return m.soloVisibility != nil }},
+	{keys: "c", desc: "Cumulative sum", mode: modeNormal},
+	{keys: "R", desc: "Rate of counters", mode: modeNormal},
+	{keys: "g", desc: "Log scale", mode: modeNormal},
+	{keys: "X", desc: "Relative time labels", mode: modeNormal},
+	{keys: "L", desc: "Lock/unlock series set", mode: modeNormal, bar: true, when: func(m *Model) bool { return m.seriesLocked }},
+	{keys: "a", desc: "Aggregate series", mode: modeNormal},
+	{keys: "A", desc: "Cycle sum/avg/max/min", mode: modeNormal},
+	{keys: "o", desc: "Sort legend by value", mode: modeNormal},
+	{keys: "S", desc: "Legend min/max/avg", mode: modeNormal},
+	{keys: "i", desc: "Metric TYPE/HELP", mode: modeNormal},
+	{keys: "Y", desc: "Fit Y axis", mode: modeNormal},
+	{keys: "D", desc: "Connection diagnostics", mode: modeNormal},
+	{keys: "x", desc: "Correlation of marked series", mode: modeNormal},
+	{keys: "F", desc: "Full name of hovered series", mode: modeNormal},
+	{keys: "y", desc: "Copy value of hovered series", mode: modeNormal},
+	{keys: "C", desc: "Copy URL, metric and visible series", mode: modeNormal},
+	{keys: "M", desc: "Annotate current time", mode: modeNormal},
+	{keys: "U", desc: "Clear annotations", mode: modeNormal},
+	{keys: "h", desc: "Histogram of hovered series", mode: modeNormal},
+	{keys: "B", desc: "Bucket distribution", mode: modeNormal, bar: true, when: func(m *Model) bool { return m.hasBuckets() }},
+	{keys: "W", desc: "Export data (remote-write)", mode: modeNormal},
+	{keys: "e", desc: "Export visible series (CSV)", mode: modeNormal},
+	{keys: "+/-", desc: "Zoom time axis in/out", mode: modeNormal},
+	{keys: "[/]", desc: "Step back/forward a scrape", mode: modeNormal},
+	{keys: "</>", desc: "Halve/double scrape interval", mode: modeNormal},
+	{keys: "P", desc: "Switch to next profile", mode: modeNormal},
+	{keys: "p", desc: "Pause/resume", mode: modeNormal},
+	{keys: "f", desc: "Fetch once", mode: modeNormal, bar: true, when: func(m *Model) bool { return m.paused }},
+	{keys: "f", desc: "Follow live", mode: modeNormal, bar: true, when: func(m *Model) bool { return !m.paused && !m.following() }},
+	{keys: "↑↓", desc: "Scroll legend", mode: modeNormal, bar: true, when: func(m *Model) bool {
+		return m.showLegend && m.legendViewport.TotalLineCount() > m.legendViewport.VisibleLineCount()
+	}},
+
+	{keys: "Space", desc: "Toggle", mode: modeSeriesSelect, bar: true},
+	{keys: "Enter", desc: "Accept", mode: modeSeriesSelect, bar: true},
+	{keys: "a", desc: "Toggle All", mode: modeSeriesSelect, bar: true},
+	{keys: "/", desc: "Filter", mode: modeSeriesSelect, bar: true},
+	{keys: "x", desc: "Mark for correlation", mode: modeSeriesSelect},
+	{keys: "n/v", desc: "Sort by name/value (again to reverse)", mode: modeSeriesSelect},
+	{keys: "y", desc: "Copy current value", mode: modeSeriesSelect},
+	{keys: "h", desc: "Value histogram", mode: modeSeriesSelect},
+	{keys: "r", desc: "Use as 100% reference", mode: modeSeriesSelect},
+	{keys: "Esc/q", desc: "Cancel", mode: modeSeriesSelect, bar: true},
+	{keys: "↑↓", desc: "Navigate", mode: modeSeriesSelect, bar: true},
+
+	{keys: "Enter", desc: "Select", mode: modeMetricSelect, bar: true},
+	{keys: "Esc/q", desc: "Cancel", mode: modeMetricSelect, bar: true},
+	{keys: "/", desc: "Filter", mode: modeMetricSelect, bar: true},
+}
+
+// helpBarBindings returns the bindings shown in the help bar of a mode
+func (m *Model) helpBarBindings(mode keyMode) []keyBinding {
+	var bindings []keyBinding
+	for _, b := range keyBindings {
+		if b.mode != mode || !b.bar {
+			continue
+		}
+		if b.when != nil && !b.when(m) {
+			continue
+		}
+		bindings = append(bindings, b)
+	}
+	return bindings
+}
+
+// pausedStyle marks the help bar while scraping is paused
+var pausedStyle = lipgloss.NewStyle().Background(accentColor).Foreground(lipgloss.Color("0")).Bold(true).Padding(0, 1)
+
+// helpBarContent renders the help bar of the chart view
+func (m *Model) helpBarContent() string {
+	keyStyle := lipgloss.NewStyle().Background(lipgloss.Color("237")).Foreground(lipgloss.Color("15")).Bold(true)
+	valStyle := lipgloss.NewStyle().Background(lipgloss.Color("15")).Foreground(lipgloss.Color("0"))
+
+	var parts []string
+	if m.paused {
+		parts = append(parts, pausedStyle.Render("PAUSED"))
+	}
+	for _, b := range m.helpBarBindings(modeNormal) {
+		parts = append(parts, keyStyle.Render(b.keys)+valStyle.Render(b.desc))
+	}
+	return strings.Join(parts, "  ")
+}
+
+// modeHelpContent renders the single line help of the selection modes
+func (m *Model) modeHelpContent(mode keyMode) string {
+	var parts []string
+	for _, b := range m.helpBarBindings(mode) {
+		parts = append(parts, fmt.Sprintf("%s: %s", b.keys, b.desc))
+	}
+	return helpStyle.Render(strings.Join(parts, " | "))
+}
+
+// helpOverlayView renders all keybindings grouped by mode
+func (m *Model) helpOverlayView() string {
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render("Keybindings"))
+	sb.WriteString("\n")
+
+	for _, mode := range []keyMode{modeNormal, modeSeriesSelect, modeMetricSelect} {
+		sb.WriteString("\n")
+		sb.WriteString(titleStyle.Render(keyModeTitles[mode]))
+		sb.WriteString("\n")
+		for _, b := range keyBindings {
+			if b.mode != mode {
+				continue
+			}
+			keys := lipgloss.NewStyle().Width(9).Render(b.keys)
+			sb.WriteString(listItemStyle.Render(keys + b.desc))
+			sb.WriteString("\n")
+		}
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(helpStyle.Render("Press ?, Esc or q to close"))
+	return sb.String()
+}
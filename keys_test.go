@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHelpBarBindings(t *testing.T) {
+	m := NewModel("http://localhost", "metric", time.Second)
+
+	hasKeys := func(bindings []keyBinding, keys string) bool {
+		for _, b := range bindings {
+			if b.keys == keys {
+				return true
+			}
+		}
+		return false
+	}
+
+	bindings := m.helpBarBindings(modeNormal)
+	if !hasKeys(bindings, "q") || !hasKeys(bindings, "?") {
+		t.Fatalf("expected quit and help in the help bar, got %v", bindings)
+	}
+	if hasKeys(bindings, "esc") {
+		t.Fatalf("expected exit solo to be hidden while not soloing")
+	}
+	if hasKeys(bindings, "Space") {
+		t.Fatalf("expected series selection bindings to be excluded")
+	}
+
+	m.soloVisibility = map[string]bool{}
+	if !hasKeys(m.helpBarBindings(modeNormal), "esc") {
+		t.Fatalf("expected exit solo to be shown while soloing")
+	}
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// labelValue returns the value of the given label from a full series name
+// like `metric{key="value"}`
+func labelValue(fullName, key string) (string, bool) {
+	for _, label := range seriesLabels(fullName) {
+		if label[0] == key {
+			return label[1], true
+		}
+	}
+	return "", false
+}
+
+// seriesLabels returns all label pairs of a full series name in their original order
+func seriesLabels(fullName string) [][2]string {
+	start := strings.Index(fullName, "{")
+	if start == -1 {
+		return nil
+	}
+	rest := fullName[start+1:]
+
+	var labels [][2]string
+	for len(rest) > 0 {
+		rest = strings.TrimLeft(rest, ", ")
+		eq := strings.Index(rest, "=\"")
+		if eq == -1 {
+			break
+		}
+		name := rest[:eq]
+		rest = rest[eq+2:]
+
+		// Find the closing quote, unescaping \n, \\ and \" on the way
+		var value strings.Builder
+		end := -1
+		for i := 0; i < len(rest); i++ {
+			if rest[i] == '\\' && i+1 < len(rest) {
+				if rest[i+1] == 'n' {
+					value.WriteByte('\n')
+				} else {
+					value.WriteByte(rest[i+1])
+				}
+				i++
+				continue
+			}
+			if rest[i] == '"' {
+				end = i
+				break
+			}
+			value.WriteByte(rest[i])
+		}
+		if end == -1 {
+			break
+		}
+		labels = append(labels, [2]string{name, value.String()})
+		rest = rest[end+1:]
+	}
+	return labels
+}
+
+// parseLabels splits a full series name into the metric name and its labels
+func parseLabels(fullName string) (string, map[string]string) {
+	base, _, _ := strings.Cut(fullName, "{")
+	labels := make(map[string]string)
+	for _, label := range seriesLabels(fullName) {
+		labels[label[0]] = label[1]
+	}
+	return base, labels
+}
+
+// labelPairs renders the labels of a series as `key=value` pairs, showing summary
+// quantiles as percentiles and the metric name for series without labels
+func labelPairs(fullName string) string {
+	var parts []string
+	q, isQuantile := quantileOf(fullName)
+	if isQuantile {
+		parts = append(parts, formatQuantile(q))
+	}
+	for _, label := range seriesLabels(fullName) {
+		if isQuantile && label[0] == "quantile" {
+			continue
+		}
+		parts = append(parts, label[0]+"="+label[1])
+	}
+
+	if len(parts) == 0 {
+		base, _, _ := strings.Cut(fullName, "{")
+		return base
+	}
+	return strings.Join(parts, " ")
+}
+
+// mixedMetrics reports whether the series belong to more than one metric, e.g.
+// for a comma-separated --metric or a prefix match
+func (m *Model) mixedMetrics() bool {
+	first := ""
+	for i, series := range m.seriesList {
+		base, _, _ := strings.Cut(series.name, "{")
+		if i == 0 {
+			first = base
+		} else if base != first {
+			return true
+		}
+	}
+	return false
+}
+
+// metricLabel returns the label pairs of a series, prefixed with its metric name
+// if the chart mixes several metrics
+func metricLabel(fullName string, mixed bool) string {
+	pairs := labelPairs(fullName)
+	base, _, _ := strings.Cut(fullName, "{")
+	if !mixed || pairs == base {
+		return pairs
+	}
+	return base + " " + pairs
+}
+
+// quantileOf returns the parsed quantile label of a series
+func quantileOf(fullName string) (float64, bool) {
+	raw, ok := labelValue(fullName, "quantile")
+	if !ok {
+		return 0, false
+	}
+	q, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, false
+	}
+	return q, true
+}
+
+// formatQuantile formats a quantile like 0.99 as a percentile like "p99"
+func formatQuantile(q float64) string {
+	percentile := math.Round(q*100*1000) / 1000
+	return "p" + strconv.FormatFloat(percentile, 'f', -1, 64)
+}
+
+// withoutLabel removes a single label from a full series name
+func withoutLabel(fullName, key string) string {
+	value, ok := labelValue(fullName, key)
+	if !ok {
+		return fullName
+	}
+	pair := fmt.Sprintf("%s=%q", key, value)
+	result := strings.Replace(fullName, pair+",", "", 1)
+	if result == fullName {
+		result = strings.Replace(fullName, ","+pair, "", 1)
+	}
+	if result == fullName {
+		result = strings.Replace(fullName, pair, "", 1)
+	}
+	return result
+}
+
+// withLabel adds a label in front of the labels of a full series name. A label
+// of the same name is kept as exported_<key>, like Prometheus does.
+func withLabel(fullName, key, value string) string {
+	base, _, _ := strings.Cut(fullName, "{")
+	pairs := []string{fmt.Sprintf("%s=%q", key, value)}
+	for _, label := range seriesLabels(fullName) {
+		if label[0] == key {
+			label[0] = "exported_" + key
+		}
+		pairs = append(pairs, fmt.Sprintf("%s=%q", label[0], label[1]))
+	}
+	return base + "{" + strings.Join(pairs, ",") + "}"
+}
+
+// seriesDisplayName returns a human friendly name for a series, showing
+// summary quantiles as percentiles (e.g. `p99`) instead of the raw label
+func seriesDisplayName(fullName string) string {
+	q, ok := quantileOf(fullName)
+	if !ok {
+		return fullName
+	}
+	rest := withoutLabel(fullName, "quantile")
+	if strings.HasSuffix(rest, "{}") {
+		return formatQuantile(q)
+	}
+	if idx := strings.Index(rest, "{"); idx != -1 {
+		rest = rest[idx:]
+	}
+	return formatQuantile(q) + " " + rest
+}
+
+// quantileGroup returns the summary a quantile series belongs to, which is the
+// series name without the quantile label
+func quantileGroup(fullName string) (string, bool) {
+	if _, ok := quantileOf(fullName); !ok {
+		return "", false
+	}
+	return withoutLabel(fullName, "quantile"), true
+}
+
+// sortSeriesByQuantile groups summary series by the summary they belong to, in
+// order of first appearance, and orders each group by quantile. The relative
+// order of all other series is kept.
+func sortSeriesByQuantile(series []seriesItem) {
+	groups := make(map[string]int)
+	for _, s := range series {
+		if group, ok := quantileGroup(s.name); ok {
+			if _, seen := groups[group]; !seen {
+				groups[group] = len(groups)
+			}
+		}
+	}
+
+	sort.SliceStable(series, func(i, j int) bool {
+		qi, okI := quantileOf(series[i].name)
+		qj, okJ := quantileOf(series[j].name)
+		if !okI || !okJ {
+			return okI && !okJ
+		}
+		gi, _ := quantileGroup(series[i].name)
+		gj, _ := quantileGroup(series[j].name)
+		if groups[gi] != groups[gj] {
+			return groups[gi] < groups[gj]
+		}
+		return qi < qj
+	})
+}
+
+// collapseSeries re-keys samples by only the given labels, summing the values of
+// series that end up with the same identity. The order of first appearance is kept.
+func collapseSeries(samples []MetricSample, by []string) []MetricSample {
+	var result []MetricSample
+	index := make(map[string]int)
+
+	for _, sample := range samples {
+		baseName := sample.FullName
+		if idx := strings.Index(baseName, "{"); idx != -1 {
+			baseName = baseName[:idx]
+		}
+
+		var pairs []string
+		for _, label := range by {
+			if value, ok := labelValue(sample.FullName, label); ok {
+				pairs = append(pairs, fmt.Sprintf("%s=%q", label, value))
+			}
+		}
+		name := baseName + "{" + strings.Join(pairs, ",") + "}"
+
+		if i, exists := index[name]; exists {
+			result[i].Value += sample.Value
+			continue
+		}
+		index[name] = len(result)
+		_, labels := parseLabels(name)
+		result = append(result, MetricSample{FullName: name, Labels: labels, Value: sample.Value})
+	}
+
+	return result
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLabelValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		fullName string
+		key      string
+		want     string
+		wantOK   bool
+	}{
+		{"single label", `rpc_duration{quantile="0.5"}`, "quantile", "0.5", true},
+		{"multiple labels", `rpc_duration{method="GET",quantile="0.99"}`, "quantile", "0.99", true},
+		{"comma in value", `rpc_duration{path="/a,b",quantile="0.9"}`, "quantile", "0.9", true},
+		{"escaped quote", `rpc_duration{path="say \"hi\""}`, "path", `say "hi"`, true},
+		{"missing label", `rpc_duration{method="GET"}`, "quantile", "", false},
+		{"no labels", `rpc_duration{}`, "quantile", "", false},
+		{"bare name", `rpc_duration`, "quantile", "", false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := labelValue(tt.fullName, tt.key)
+			if ok != tt.wantOK {
+				t.Fatalf("expected ok=%v, got %v", tt.wantOK, ok)
+			}
+			if got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSeriesLabels(t *testing.T) {
+	got := seriesLabels(`http_requests{method="GET",path="/a,\"b\""}`)
+	want := [][2]string{{"method", "GET"}, {"path", `/a,"b"`}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	got = seriesLabels(`log_messages{msg="line 1\nline 2",path="C:\\logs"}`)
+	want = [][2]string{{"msg", "line 1\nline 2"}, {"path", `C:\logs`}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected escaped newlines and backslashes to be decoded, got %q", got)
+	}
+	if got := seriesLabels(`http_requests{}`); got != nil {
+		t.Fatalf("expected no labels, got %v", got)
+	}
+}
+
+func TestParseLabels(t *testing.T) {
+	base, labels := parseLabels(`http_requests{query="a=b",msg="say \"hi\""}`)
+	if base != "http_requests" {
+		t.Fatalf("expected base http_requests, got %s", base)
+	}
+	want := map[string]string{"query": "a=b", "msg": `say "hi"`}
+	if !reflect.DeepEqual(labels, want) {
+		t.Fatalf("expected %v, got %v", want, labels)
+	}
+
+	base, labels = parseLabels("up")
+	if base != "up" || len(labels) != 0 {
+		t.Fatalf("expected bare name without labels, got %s %v", base, labels)
+	}
+}
+
+func TestLabelPairs(t *testing.T) {
+	tests := []struct {
+		fullName string
+		want     string
+	}{
+		{`http_requests{method="GET",code="200"}`, "method=GET code=200"},
+		{`rpc_duration{method="GET",quantile="0.99"}`, "p99 method=GET"},
+		{`http_requests{}`, "http_requests"},
+		{`up`, "up"},
+	}
+
+	for _, tt := range tests {
+		if got := labelPairs(tt.fullName); got != tt.want {
+			t.Fatalf("labelPairs(%s): expected %s, got %s", tt.fullName, tt.want, got)
+		}
+	}
+}
+
+func TestMetricLabel(t *testing.T) {
+	if got := metricLabel(`cpu_usage{instance="a"}`, false); got != "instance=a" {
+		t.Fatalf("expected only the label pairs of a single metric, got %s", got)
+	}
+	if got := metricLabel(`cpu_usage{instance="a"}`, true); got != "cpu_usage instance=a" {
+		t.Fatalf("expected the metric name in front of the label pairs, got %s", got)
+	}
+	if got := metricLabel(`mem_usage{}`, true); got != "mem_usage" {
+		t.Fatalf("expected the metric name once, got %s", got)
+	}
+}
+
+func TestFormatQuantile(t *testing.T) {
+	tests := []struct {
+		q    float64
+		want string
+	}{
+		{0.5, "p50"},
+		{0.9, "p90"},
+		{0.99, "p99"},
+		{0.999, "p99.9"},
+		{0, "p0"},
+		{1, "p100"},
+	}
+
+	for _, tt := range tests {
+		if got := formatQuantile(tt.q); got != tt.want {
+			t.Fatalf("formatQuantile(%v): expected %s, got %s", tt.q, tt.want, got)
+		}
+	}
+}
+
+func TestSeriesDisplayName(t *testing.T) {
+	tests := []struct {
+		fullName string
+		want     string
+	}{
+		{`rpc_duration{quantile="0.99"}`, "p99"},
+		{`rpc_duration{method="GET",quantile="0.5"}`, `p50 {method="GET"}`},
+		{`rpc_duration{quantile="0.9",method="GET"}`, `p90 {method="GET"}`},
+		{`rpc_duration{method="GET"}`, `rpc_duration{method="GET"}`},
+		{`rpc_duration{quantile="abc"}`, `rpc_duration{quantile="abc"}`},
+	}
+
+	for _, tt := range tests {
+		if got := seriesDisplayName(tt.fullName); got != tt.want {
+			t.Fatalf("seriesDisplayName(%s): expected %s, got %s", tt.fullName, tt.want, got)
+		}
+	}
+}
+
+func TestSortSeriesByQuantile(t *testing.T) {
+	series := []seriesItem{
+		{name: `rpc_duration{quantile="0.99"}`, colorIdx: 0},
+		{name: `rpc_duration{quantile="0.5"}`, colorIdx: 1},
+		{name: `rpc_duration{quantile="0.9"}`, colorIdx: 2},
+	}
+
+	sortSeriesByQuantile(series)
+
+	want := []string{
+		`rpc_duration{quantile="0.5"}`,
+		`rpc_duration{quantile="0.9"}`,
+		`rpc_duration{quantile="0.99"}`,
+	}
+	for i, name := range want {
+		if series[i].name != name {
+			t.Fatalf("position %d: expected %s, got %s", i, name, series[i].name)
+		}
+	}
+	if series[0].colorIdx != 1 {
+		t.Fatalf("expected color index to move with the series, got %d", series[0].colorIdx)
+	}
+}
+
+func TestSortSeriesByQuantileGroups(t *testing.T) {
+	series := []seriesItem{
+		{name: `rpc_duration{method="POST",quantile="0.9"}`},
+		{name: `rpc_duration_count{method="POST"}`},
+		{name: `rpc_duration{method="GET",quantile="0.9"}`},
+		{name: `rpc_duration{method="POST",quantile="0.5"}`},
+		{name: `rpc_duration{method="GET",quantile="0.5"}`},
+	}
+
+	sortSeriesByQuantile(series)
+
+	want := []string{
+		`rpc_duration{method="POST",quantile="0.5"}`,
+		`rpc_duration{method="POST",quantile="0.9"}`,
+		`rpc_duration{method="GET",quantile="0.5"}`,
+		`rpc_duration{method="GET",quantile="0.9"}`,
+		`rpc_duration_count{method="POST"}`,
+	}
+	for i, name := range want {
+		if series[i].name != name {
+			t.Fatalf("position %d: expected %s, got %s", i, name, series[i].name)
+		}
+	}
+}
+
+func TestCollapseSeries(t *testing.T) {
+	samples := []MetricSample{
+		{FullName: `http_requests{instance="a",method="GET",code="200"}`, Value: 1},
+		{FullName: `http_requests{instance="b",method="GET",code="200"}`, Value: 2},
+		{FullName: `http_requests{instance="a",method="POST",code="500"}`, Value: 3},
+		{FullName: `http_requests{}`, Value: 4},
+	}
+
+	got := collapseSeries(samples, []string{"instance"})
+	want := []MetricSample{
+		{FullName: `http_requests{instance="a"}`, Labels: map[string]string{"instance": "a"}, Value: 4},
+		{FullName: `http_requests{instance="b"}`, Labels: map[string]string{"instance": "b"}, Value: 2},
+		{FullName: `http_requests{}`, Labels: map[string]string{}, Value: 4},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestWithLabel(t *testing.T) {
+	tests := []struct {
+		fullName string
+		want     string
+	}{
+		{`up{instance="a"}`, `up{source="host:9090",instance="a"}`},
+		{`up{}`, `up{source="host:9090"}`},
+		{`up`, `up{source="host:9090"}`},
+		{`up{source="app",instance="a"}`, `up{source="host:9090",exported_source="app",instance="a"}`},
+	}
+
+	for _, tt := range tests {
+		if got := withLabel(tt.fullName, "source", "host:9090"); got != tt.want {
+			t.Fatalf("withLabel(%s): expected %s, got %s", tt.fullName, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/NimbleMarkets/ntcharts/linechart/timeserieslinechart"
+)
+
+// seriesStats returns the minimum, maximum and average value of the points
+func seriesStats(points []timeserieslinechart.TimePoint) (lo, hi, avg float64, ok bool) {
+	if len(points) == 0 {
+		return 0, 0, 0, false
+	}
+
+	lo, hi = math.Inf(1), math.Inf(-1)
+	sum := 0.0
+	for _, p := range points {
+		lo = math.Min(lo, p.Value)
+		hi = math.Max(hi, p.Value)
+		sum += p.Value
+	}
+	return lo, hi, sum / float64(len(points)), true
+}
+
+// siSuffixes are the suffixes of compactValue for each power of 1000
+var siSuffixes = []string{"", "k", "M", "G", "T", "P", "E"}
+
+// compactValue formats a value with 3 significant digits, shortening large
+// values with an SI suffix so the stats fit the legend
+func compactValue(v float64) string {
+	i := 0
+	for math.Abs(v) >= 999.5 && i < len(siSuffixes)-1 {
+		v /= 1000
+		i++
+	}
+	return strconv.FormatFloat(v, 'g', 3, 64) + siSuffixes[i]
+}
+
+// statsLine renders the range and average of the points, e.g. "1.2–4.5 ⌀3.1"
+func statsLine(points []timeserieslinechart.TimePoint) string {
+	lo, hi, avg, ok := seriesStats(points)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%s–%s ⌀%s", compactValue(lo), compactValue(hi), compactValue(avg))
+}
+
+// legendStats returns the stats line of every plotted line by name, or nil if
+// the stats are hidden
+func (m *Model) legendStats() map[string]string {
+	if !m.showStats {
+		return nil
+	}
+	stats := make(map[string]string)
+	for _, line := range m.plotLines() {
+		stats[line.name] = statsLine(line.points)
+	}
+	return stats
+}
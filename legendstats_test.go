@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestSeriesStats(t *testing.T) {
+	lo, hi, avg, ok := seriesStats(trendPoints(4, -2, 10, 0))
+	if !ok || lo != -2 || hi != 10 || avg != 3 {
+		t.Fatalf("expected -2, 10, 3, got %v, %v, %v (%v)", lo, hi, avg, ok)
+	}
+	if _, _, _, ok := seriesStats(nil); ok {
+		t.Fatal("expected no stats without points")
+	}
+}
+
+func TestCompactValue(t *testing.T) {
+	tests := []struct {
+		value float64
+		want  string
+	}{
+		{0, "0"},
+		{0.001234, "0.00123"},
+		{42.5, "42.5"},
+		{999.4, "999"},
+		{999.6, "1k"},
+		{1234567, "1.23M"},
+		{-25300, "-25.3k"},
+	}
+	for _, tt := range tests {
+		if got := compactValue(tt.value); got != tt.want {
+			t.Errorf("compactValue(%v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestStatsLineFitsLegend(t *testing.T) {
+	width, _ := legendInnerDimensions(legendBoxWidth, 20)
+	line := "  " + statsLine(trendPoints(-1234567, 0.000123456, 987654321))
+	if got := len([]rune(line)); got > width {
+		t.Fatalf("expected the stats to fit %d columns, got %d: %q", width, got, line)
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// localPath returns the path of a metrics file given as file:// URL or as the
+// path of an existing file
+func localPath(target string) (string, bool) {
+	if path, ok := strings.CutPrefix(target, "file://"); ok {
+		return path, true
+	}
+	if strings.Contains(target, "://") {
+		return "", false
+	}
+	info, err := os.Stat(target)
+	if err != nil || info.IsDir() {
+		return "", false
+	}
+	return target, true
+}
+
+// openFile reads a metrics file as if it was the response of an endpoint, so it
+// is parsed like a scrape. The file is read again on every scrape, so a file
+// rewritten by a script can be watched.
+func openFile(path string) (*http.Response, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open metrics file: %w", err)
+	}
+	body, err := gunzip(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to decompress metrics file: %w", err)
+	}
+	return &http.Response{Status: "200 OK", StatusCode: http.StatusOK, Header: http.Header{}, Body: body}, nil
+}
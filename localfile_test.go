@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalPath(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "node.prom")
+	if err := os.WriteFile(file, []byte("up 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		target string
+		want   string
+		wantOK bool
+	}{
+		{file, file, true},
+		{"file://" + file, file, true},
+		{"http://localhost:9100/metrics", "", false},
+		{filepath.Join(dir, "missing.prom"), "", false},
+		{dir, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := localPath(tt.target)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("localPath(%q) = %q, %v, want %q, %v", tt.target, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestFetchFromFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "node.prom")
+	if err := os.WriteFile(file, []byte("up{job=\"node\"} 1\nbuild_info 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := fetchAllMetrics(fetchConfig{}, file)
+	if err != nil || len(names) != 2 {
+		t.Fatalf("expected both metrics of the file, got %v (%v)", names, err)
+	}
+
+	// Every scrape reads the file again
+	if err := os.WriteFile(file, []byte("up{job=\"node\"} 0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	samples, err := fetchAllMetricSeries(fetchConfig{}, "file://"+file, "up", matchExact)
+	if err != nil || len(samples) != 1 || samples[0].Value != 0 {
+		t.Fatalf("expected the rewritten value, got %v (%v)", samples, err)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"math"
+
+	"github.com/NimbleMarkets/ntcharts/linechart/timeserieslinechart"
+)
+
+// logPoints maps the values of the points to log10. The logarithm of zero and
+// negative values is undefined, so those points are left out.
+func logPoints(points []timeserieslinechart.TimePoint) []timeserieslinechart.TimePoint {
+	result := make([]timeserieslinechart.TimePoint, 0, len(points))
+	for _, p := range points {
+		if p.Value <= 0 {
+			continue
+		}
+		result = append(result, timeserieslinechart.TimePoint{Time: p.Time, Value: math.Log10(p.Value)})
+	}
+	return result
+}
+
+// logYLabelFormatter labels a log10 scaled Y axis with the unscaled values
+func logYLabelFormatter(linear func(int, float64) string) func(int, float64) string {
+	return func(i int, v float64) string {
+		return linear(i, math.Pow(10, v))
+	}
+}
+
+// chartLines returns the plotted lines as they are pushed to the chart, which
+// is on a log10 scale if enabled
+func (m *Model) chartLines() []plotLine {
+	lines := m.plotLines()
+	if m.logScale {
+		for i := range lines {
+			lines[i].points = logPoints(lines[i].points)
+		}
+	}
+	return lines
+}
+
+// chartValue maps a value to the scale of the chart. Zero and negative values
+// have no place on a log scale.
+func (m *Model) chartValue(v float64) (float64, bool) {
+	if !m.logScale {
+		return v, true
+	}
+	if v <= 0 {
+		return 0, false
+	}
+	return math.Log10(v), true
+}
+
+// applyYScale labels the Y axis of the chart for the current scale
+func (m *Model) applyYScale() {
+	if m.logScale {
+		m.chart.YLabelFormatter = logYLabelFormatter(m.yFormatter())
+	} else {
+		m.chart.YLabelFormatter = m.yFormatter()
+	}
+}
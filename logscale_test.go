@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	zone "github.com/lrstanley/bubblezone"
+)
+
+func TestLogPoints(t *testing.T) {
+	got := logPoints(trendPoints(100, 0, -5, 1000))
+	if len(got) != 2 || got[0].Value != 2 || got[1].Value != 3 {
+		t.Fatalf("expected log10 of the positive values only, got %v", got)
+	}
+}
+
+func TestLogYLabelFormatter(t *testing.T) {
+	formatter := logYLabelFormatter(yLabelFormatter(adaptivePrecision))
+	if got := formatter(0, 3); got != "1000" {
+		t.Fatalf("expected the unscaled value, got %q", got)
+	}
+	if got := formatter(0, -1); got != "0.10" {
+		t.Fatalf("expected the unscaled small value, got %q", got)
+	}
+}
+
+func TestLogScaleFitsLogValues(t *testing.T) {
+	zone.NewGlobal()
+	m := NewModel("http://localhost", "up", time.Second)
+	m.logScale = true
+	m.applyYScale()
+
+	start := time.Unix(1000, 0)
+	for i, value := range []float64{10, 0, 100000} {
+		model, _ := m.Update(MetricsMsg{
+			Samples: []MetricSample{{FullName: `up{instance="a"}`, Value: value}},
+			Time:    start.Add(time.Duration(i) * time.Second),
+		})
+		m = model.(Model)
+	}
+
+	if m.chart.ViewMinY() > 1 || m.chart.ViewMaxY() < 5 || m.chart.ViewMaxY() > 10 {
+		t.Fatalf("expected a range around log10 of 10 to 100000, got %v to %v", m.chart.ViewMinY(), m.chart.ViewMaxY())
+	}
+	if got := len(m.dataHistory[`up{instance="a"}`]); got != 3 {
+		t.Fatalf("expected the raw history to keep all points, got %d", got)
+	}
+}
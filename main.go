@@ -3,35 +3,43 @@ package main
 import (
 	"fmt"
 	"io"
+	"log"
 	"math"
+	"net/http"
 	"os"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/NimbleMarkets/ntcharts/canvas/runes"
 	"github.com/NimbleMarkets/ntcharts/linechart/timeserieslinechart"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	zone "github.com/lrstanley/bubblezone"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // Styles
 var (
+	accentColor     = lipgloss.Color("#ff5f00")
 	backgroundColor = lipgloss.Color("#282A35")
 	defaultStyle    = lipgloss.NewStyle().Background(backgroundColor)
 	titleStyle      = lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("#ff5f00")).Background(backgroundColor)
+			Foreground(accentColor).Background(backgroundColor)
 
 	borderStyle = lipgloss.NewStyle().
 			BorderStyle(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("#ff5f00"))
+			BorderForeground(accentColor)
 
 	graphStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#ff5f00"))
+			Foreground(accentColor)
 
 	axisStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#808080"))
@@ -44,36 +52,157 @@ var (
 			Foreground(lipgloss.Color("15"))
 
 	listItemStyle         = lipgloss.NewStyle().PaddingLeft(2)
-	listSelectedItemStyle = lipgloss.NewStyle().PaddingLeft(2).Foreground(lipgloss.Color("#ff5f00"))
-	listTitleStyle        = lipgloss.NewStyle().MarginLeft(2).Bold(true).Foreground(lipgloss.Color("#ff5f00"))
+	listSelectedItemStyle = lipgloss.NewStyle().PaddingLeft(2).Foreground(accentColor)
+	listTitleStyle        = lipgloss.NewStyle().MarginLeft(2).Bold(true).Foreground(accentColor)
+	helpTextStyle         = lipgloss.NewStyle().PaddingLeft(5).Foreground(lipgloss.Color("#808080"))
 )
 
 const (
-	legendBoxWidth   = 35
+	legendBoxWidth   = 35 // Default width of the legend box, see --legend-width
+	minLegendWidth   = 20
+	minLegendLabel   = 4 // Narrowest legend label, which leaves one character and "..."
 	legendContentPad = 1
+	maxChartAspect   = 4 // Maximum width to height ratio of a chart with capped width
 )
 
 var (
-	metricFlag   string
-	intervalFlag time.Duration
-	rootCmd      = &cobra.Command{
-		Use:   "slashmetrics <url>",
+	metricFlag      string
+	metricMatchFlag string
+	queryFlag       string
+	intervalFlag    time.Duration
+	autoSelectFlag  string
+	noBorderFlag    bool
+	noLogoFlag      bool
+	onceFlag        bool
+	listFlag        bool
+	noStateFlag     bool
+	staleFlag       int
+	themeFlag       string
+	noColorFlag     bool
+	maxSeriesFlag   int
+	seriesFlag      string
+	dupPolicyFlag   string
+	unitFlag        string
+	precisionFlag   int
+	legendMaxFlag   int
+	legendWidthFlag int
+	aggregateFlag   string
+	groupByFlag     []string
+	byFlag          []string
+	methodFlag      string
+	bodyFlag        string
+	filterFlag      string
+	maxMetricsFlag  int
+	includeInfoFlag bool
+	highlightFlag   string
+	bucketsFlag     int
+	trendWindowFlag time.Duration
+	legendStatsFlag bool
+	sparklineFlag   int
+	logScaleFlag    bool
+	xRelativeFlag   bool
+	manualFlag      bool
+	yMinFlag        float64
+	yMaxFlag        float64
+	thresholdFlag   float64
+	thresholdOpFlag string
+	maxPointsFlag   int
+	exportPathFlag  string
+	rateFlag        bool
+	userAgentFlag   string
+	userFlag        string
+	tokenFlag       string
+	tokenFileFlag   string
+	headerFlag      []string
+	timeoutFlag     time.Duration
+	remoteWriteFlag string
+	insecureFlag    bool
+	proxyFlag       string
+	execCredFlag    string
+	execCredTTLFlag time.Duration
+	configFlag      string
+	profileFlag     string
+	rootCmd         = &cobra.Command{
+		Use:   "slashmetrics [url|file...]",
 		Short: "Terminal-based Prometheus metric explorer",
-		Args:  cobra.ExactArgs(1),
+		Long:  "Terminal-based Prometheus metric explorer. Given several URLs, the metric is scraped from all of them and series are labeled with their source host. Files (as path or file:// URL) are read again on every scrape.",
+		Args:  cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runApp(args[0])
+			return runApp(cmd.Flags(), args)
 		},
 	}
 )
 
 func init() {
-	rootCmd.Flags().StringVar(&metricFlag, "metric", "", "The metric to visualize (if empty, a random metric will be chosen)")
+	rootCmd.Flags().StringVar(&configFlag, "config", defaultConfigPath(), "Config file with named profiles")
+	rootCmd.Flags().StringVar(&profileFlag, "profile", "", "Profile of the config file to start with, flags given on the command line take precedence")
+	rootCmd.Flags().StringVar(&metricFlag, "metric", "", "The metric to visualize, or several separated by commas to overlay them (if empty, a random metric will be chosen)")
+	rootCmd.Flags().StringVar(&metricMatchFlag, "metric-match", string(matchExact), "How --metric is matched against metric names (exact, prefix, regex)")
+	rootCmd.Flags().StringVar(&queryFlag, "query", "", "PromQL expression evaluated with the query API of a Prometheus server at the URL instead of scraping a metric")
 	rootCmd.Flags().DurationVar(&intervalFlag, "interval", 2*time.Second, "The interval to poll for new metrics")
+	rootCmd.Flags().StringVar(&autoSelectFlag, "auto-select", "first", "How to pick a metric when --metric is empty (first, active)")
+	rootCmd.Flags().BoolVar(&noBorderFlag, "no-border", false, "Hide the border around the chart")
+	rootCmd.Flags().BoolVar(&noLogoFlag, "no-logo", false, "Replace the ASCII logo with a single-line title for more vertical room")
+	rootCmd.Flags().BoolVar(&noColorFlag, "no-color", false, "Disable colors (also disabled by the NO_COLOR environment variable)")
+	rootCmd.Flags().StringVar(&themeFlag, "theme", defaultTheme, "Color theme ("+strings.Join(themeNames(), ", ")+")")
+	rootCmd.Flags().IntVar(&staleFlag, "stale-scrapes", 3, "Failed scrapes in a row that only mark the chart as stale before the error is shown")
+	rootCmd.Flags().BoolVar(&noStateFlag, "no-state", false, "Don't start with the metric selected in the last run, nor remember the one of this run")
+	rootCmd.Flags().BoolVar(&manualFlag, "refresh-on-keypress", false, "Start paused, scraping only on startup and whenever f is pressed (p starts polling)")
+	rootCmd.Flags().BoolVar(&onceFlag, "once", false, "Print the series of the metric once and exit instead of starting the UI")
+	rootCmd.Flags().BoolVar(&listFlag, "list-metrics", false, "Print the names of all metrics (with TYPE and HELP, tab-separated) and exit instead of starting the UI")
+	rootCmd.PersistentFlags().StringVar(&methodFlag, "method", http.MethodGet, "The HTTP method used to scrape the endpoint")
+	rootCmd.PersistentFlags().StringVar(&userAgentFlag, "user-agent", defaultUserAgent(), "The User-Agent header sent with every scrape")
+	rootCmd.PersistentFlags().StringVar(&userFlag, "user", "", "Basic auth credentials as user:password (overrides credentials in the URL)")
+	rootCmd.PersistentFlags().StringVar(&tokenFlag, "token", "", "Bearer token sent with every scrape")
+	rootCmd.PersistentFlags().StringVar(&tokenFileFlag, "token-file", "", "File the bearer token is read from on startup (takes precedence over --token)")
+	rootCmd.PersistentFlags().StringVar(&execCredFlag, "exec-credential", "", "Command whose output (a token or a kubectl ExecCredential) is sent as bearer token")
+	rootCmd.PersistentFlags().DurationVar(&execCredTTLFlag, "exec-credential-ttl", 0, "How long to reuse a token without expiration before running the command again (0 for every scrape)")
+	rootCmd.PersistentFlags().StringArrayVar(&headerFlag, "header", nil, "Header sent with every scrape as \"Key: Value\" (repeatable)")
+	rootCmd.PersistentFlags().DurationVar(&timeoutFlag, "timeout", 10*time.Second, "Timeout of a single scrape (0 to wait forever)")
+	rootCmd.PersistentFlags().BoolVar(&insecureFlag, "insecure", false, "Skip TLS certificate verification of HTTPS endpoints (insecure, only use for self-signed certificates you trust)")
+	rootCmd.PersistentFlags().StringVar(&proxyFlag, "proxy", "", "URL of the proxy requests go through, overriding HTTP_PROXY and HTTPS_PROXY")
+	rootCmd.PersistentFlags().StringVar(&bodyFlag, "body", "", "Request body sent with every scrape (use @file to read it from a file)")
+	rootCmd.Flags().StringVar(&aggregateFlag, "aggregate", "", "Start with the series aggregated (sum, avg, min, max)")
+	rootCmd.Flags().StringSliceVar(&groupByFlag, "group-by", nil, "Labels to group by when aggregating series (implies --aggregate sum)")
+	rootCmd.Flags().StringVar(&dupPolicyFlag, "dup-policy", string(dupLast), "Value of a series listed more than once in a scrape (last, first, sum)")
+	rootCmd.Flags().IntVar(&precisionFlag, "precision", adaptivePrecision, "Decimal places of the Y axis labels regardless of magnitude (-1 to adapt them to the magnitude)")
+	rootCmd.Flags().StringVar(&unitFlag, "unit", string(unitNone), "Unit of the Y axis labels (none, bytes, duration, percent, si, or auto to pick it from the metric name suffix)")
+	rootCmd.Flags().StringSliceVar(&byFlag, "by", nil, "Only use these labels to identify series, summing series that share them")
+	rootCmd.Flags().StringVar(&filterFlag, "filter", "", "Only list metrics whose name contains this text in the metric selection")
+	rootCmd.Flags().IntVar(&maxMetricsFlag, "max-metrics", 0, "Maximum number of metrics listed in the metric selection (0 for unlimited)")
+	rootCmd.Flags().BoolVar(&includeInfoFlag, "include-info", false, "Chart info metrics instead of listing their labels")
+	rootCmd.Flags().StringVar(&seriesFlag, "series-filter", "", "Regular expression of series names (including labels) shown on startup, hiding all others")
+	rootCmd.Flags().StringVar(&highlightFlag, "highlight", "", "Regular expression of series to emphasize, dimming all others")
+	rootCmd.Flags().IntVar(&bucketsFlag, "histogram-buckets", 0, "Number of buckets of the value histogram (0 to pick automatically)")
+	rootCmd.Flags().StringVar(&remoteWriteFlag, "remote-write-url", "", "Prometheus remote-write endpoint the captured data is pushed to on export (if empty, a file is written)")
+	rootCmd.Flags().DurationVar(&trendWindowFlag, "trend-window", 0, "Lookback of the trend arrows shown in the legend, fitted through the values of that window (0 to hide them)")
+	rootCmd.Flags().BoolVar(&legendStatsFlag, "legend-stats", false, "Show the min, max and average of every series below its legend entry")
+	rootCmd.Flags().IntVar(&sparklineFlag, "sparkline", 0, "Number of recent points shown as a sparkline next to every legend entry (0 to hide them)")
+	rootCmd.Flags().BoolVar(&logScaleFlag, "log-scale", false, "Plot values on a log10 Y axis, leaving out zero and negative values")
+	rootCmd.Flags().BoolVar(&xRelativeFlag, "x-relative", false, "Label the time axis with the time before the latest scrape (e.g. -30s) instead of the wall-clock time")
+	rootCmd.Flags().Float64Var(&yMinFlag, "y-min", 0, "Fixed lower bound of the Y axis (if unset, fitted to the values)")
+	rootCmd.Flags().Float64Var(&yMaxFlag, "y-max", 0, "Fixed upper bound of the Y axis (if unset, fitted to the values)")
+	rootCmd.Flags().Float64Var(&thresholdFlag, "threshold", 0, "Alert threshold, lines beyond it are drawn in red and their legend entries flash (if unset, no threshold)")
+	rootCmd.Flags().StringVar(&thresholdOpFlag, "threshold-op", string(thresholdAbove), "Whether values greater (gt) or less (lt) than --threshold exceed it")
+	rootCmd.Flags().IntVar(&maxPointsFlag, "max-points", 1000, "Maximum number of points kept per series, dropping the oldest ones (0 for unlimited)")
+	rootCmd.Flags().BoolVar(&rateFlag, "rate", false, "Plot counters as per-second rate instead of their raw value")
+	rootCmd.Flags().StringVar(&exportPathFlag, "export-path", "", "Directory exports are written to (if empty, the working directory)")
+	rootCmd.Flags().IntVar(&maxSeriesFlag, "max-series", 0, "Maximum number of series shown by default, hiding new series with the lowest values (0 for unlimited)")
+	rootCmd.Flags().IntVar(&legendMaxFlag, "legend-max", 0, "Maximum number of series listed in the legend, ranked by current value (0 for unlimited)")
+	rootCmd.Flags().IntVar(&legendWidthFlag, "legend-width", legendBoxWidth, "Width of the legend box, wider boxes truncate fewer series names")
+
+	// Only fails for unknown flags
+	_ = rootCmd.RegisterFlagCompletionFunc("metric", completeMetrics)
+
+	rootCmd.AddCommand(versionCmd)
+	rootCmd.Version = versionText()
+	rootCmd.SetVersionTemplate("{{.Version}}\n")
 }
 
 // MetricSample represents a single metric sample
 type MetricSample struct {
-	FullName string // Full metric name including labels
+	FullName string            // Full metric name including labels
+	Labels   map[string]string // Labels parsed from FullName
 	Value    float64
 }
 
@@ -83,9 +212,18 @@ type metricItem string
 func (i metricItem) FilterValue() string { return string(i) }
 
 // metricDelegate is the list item delegate
-type metricDelegate struct{}
+type metricDelegate struct {
+	metadata map[string]MetricMeta // Metadata of the listed metrics, whose HELP is shown on a second line
+}
+
+// Height is two lines per item if there is HELP text to show
+func (d metricDelegate) Height() int {
+	if len(d.metadata) > 0 {
+		return 2
+	}
+	return 1
+}
 
-func (d metricDelegate) Height() int                             { return 1 }
 func (d metricDelegate) Spacing() int                            { return 0 }
 func (d metricDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
 func (d metricDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
@@ -104,21 +242,42 @@ func (d metricDelegate) Render(w io.Writer, m list.Model, index int, listItem li
 	}
 
 	fmt.Fprint(w, fn(str))
+	if d.Height() > 1 {
+		meta, _ := lookupMeta(d.metadata, string(i))
+		help := meta.Help
+		if maxWidth := m.Width() - 6; maxWidth > 3 && len(help) > maxWidth {
+			help = help[:maxWidth-3] + "..."
+		}
+		fmt.Fprint(w, "\n"+helpTextStyle.Render(help))
+	}
 }
 
 // TickMsg signals time to fetch new metrics
-type TickMsg time.Time
+type TickMsg struct {
+	Time time.Time
+	Gen  int // Ticks of older generations were replaced when the interval changed
+}
 
 // MetricsMsg contains fetched metrics data
 type MetricsMsg struct {
 	Samples []MetricSample
 	Err     error
+	Time    time.Time // Scrape time, defaults to when the message is handled
+
+	Diagnostics *connDiagnostics // Connection details of the scrape, if available
+}
+
+// MetadataMsg contains the TYPE and HELP metadata of all metrics
+type MetadataMsg struct {
+	Metadata map[string]MetricMeta
+	Err      error
 }
 
 // MetricsListMsg contains a list of all available metrics
 type MetricsListMsg struct {
-	Metrics []string
-	Err     error
+	Metrics  []string
+	Metadata map[string]MetricMeta // TYPE and HELP metadata per metric name
+	Err      error
 }
 
 // seriesItem represents a data series with a checked state
@@ -130,9 +289,13 @@ type seriesItem struct {
 
 // Model is the bubbletea model
 type Model struct {
-	url                string
+	url                string      // URL metrics and metadata are listed from
+	urls               []string    // URLs the metric is scraped from, starting with url
+	fetch              fetchConfig // How to request the endpoint
 	metricName         string
+	metricMatch        metricMatch // How base names are matched against metricName
 	interval           time.Duration
+	tickGen            int // Generation of the scheduled tick, bumped when the interval changes
 	chart              timeserieslinechart.Model
 	lastValues         map[string]float64                         // Map of series name to last value
 	dataHistory        map[string][]timeserieslinechart.TimePoint // Store all data points per series
@@ -142,44 +305,153 @@ type Model struct {
 	height             int
 	selectMode         bool
 	metricsList        list.Model
-	seriesSelectMode   bool         // Whether in series selection mode
-	seriesList         []seriesItem // List of available series
-	seriesListScroll   int          // Scroll position in series list
-	seriesListSelected int          // Currently selected item in series list
-	hoveredSeries      int          // Currently hovered series in legend
-	showLegend         bool         // Whether to show the legend
+	seriesSelectMode   bool                  // Whether in series selection mode
+	seriesSort         string                // Order of the series list (empty for discovery order)
+	seriesSortDesc     bool                  // Whether the series list is sorted in descending order
+	seriesList         []seriesItem          // List of available series
+	seriesListScroll   int                   // Scroll position in series list
+	seriesListSelected int                   // Currently selected item in series list
+	seriesFilter       string                // Only list series matching this text in the series selection
+	seriesFiltering    bool                  // Whether the series filter is being typed
+	hoveredSeries      int                   // Currently hovered series in legend
+	hover              *hoverPoint           // Data point under the mouse cursor on the chart, if any
+	spinner            spinner.Model         // Shown in the header while a scrape is in flight
+	annotations        []annotation          // Events marked on the time axis, kept across metrics
+	annotating         bool                  // Whether the label of a new annotation is being typed
+	annotationLabel    string                // Label of the annotation being typed
+	annotationTime     time.Time             // Time of the annotation being typed
+	fetching           bool                  // Whether a scrape is in flight
+	showLegend         bool                  // Whether to show the legend
+	hideBorder         bool                  // Whether to hide the border around the chart
+	fullscreen         bool                  // Whether only the chart and legend are shown, without header and help bar
+	hideLogo           bool                  // Whether the header shows a single-line title instead of the logo
+	soloVisibility     map[string]bool       // Visibility before solo-stepping started (nil when not soloing)
+	soloIndex          int                   // Position of the solo series among the originally visible ones
+	cumulative         bool                  // Whether to plot the running total of each series
+	windowStart        time.Time             // Points before this time are hidden (set by reset)
+	legendMax          int                   // Maximum number of legend entries (0 for unlimited)
+	legendWidth        int                   // Width of the legend box
+	showAggregated     bool                  // Whether to plot the aggregation instead of the individual series
+	aggregateOp        string                // Aggregation used for the aggregated view
+	groupBy            []string              // Labels to group by in the aggregated view
+	metadata           map[string]MetricMeta // TYPE and HELP metadata per metric name
+	showInfo           bool                  // Whether to show the TYPE and HELP of the metric above the chart
+	showHelp           bool                  // Whether the keybinding overlay is shown
+	replayFrames       []replayFrame         // Captured scrapes to replay instead of polling the URL
+	seriesBy           []string              // Labels that define series identity (empty to use all labels)
+	diagnostics        *connDiagnostics      // Connection details of the last scrape
+	showDiagnostics    bool                  // Whether the diagnostics overlay is shown
+	sortByValue        bool                  // Whether the legend lists series by current value instead of arrival order
+	metricFilter       string                // Only list metrics containing this text in the metric selection
+	maxMetrics         int                   // Maximum number of metrics in the metric selection (0 for unlimited)
+	hiddenMetrics      int                   // Number of metrics left out of the metric selection by maxMetrics
+	includeInfo        bool                  // Whether info metrics are charted like any other metric
+	infoSamples        []MetricSample        // Series of the current info metric, listed instead of charted
+	capWidth           bool                  // Whether the chart width is capped to maxChartAspect instead of filling the terminal
+	showOverview       bool                  // Whether the full history overview is shown below the chart
+	correlated         []string              // Series marked for correlation (at most two)
+	showCorrelation    bool                  // Whether the correlation overlay is shown
+	status             string                // Feedback on the last action, cleared on the next key press
+	highlight          *regexp.Regexp        // Series matching this are emphasized and all others dimmed (nil to disable)
+	seriesMatch        *regexp.Regexp        // New series not matching this are added hidden (nil to show all)
+	searching          bool                  // Whether the global search across all metrics is shown
+	searchQuery        string                // Text searched for in series names and label values
+	searchIndex        []MetricSample        // Series of all metrics to search (nil while scraping them)
+	searchErr          error                 // Error of scraping the series to search
+	searchSelected     int                   // Position of the selected match
+	histogramSeries    string                // Series whose value histogram is shown (empty when hidden)
+	showBuckets        bool                  // Whether the bucket distribution of a histogram metric is shown
+	histogramBuckets   int                   // Number of histogram buckets (0 to pick automatically)
+	trendWindow        time.Duration         // Lookback of the trend arrows in the legend (0 to hide them)
+	showStats          bool                  // Whether the min, max and average of each series are shown in the legend
+	sparkPoints        int                   // Number of points of the sparklines in the legend (0 to hide them)
+	maxPoints          int                   // Maximum number of points kept per series (0 for unlimited)
+	evictedScrapes     int                   // Scrapes that dropped points since the chart was last rebuilt
+	profile            string                // Name of the active profile (empty without one)
+	profiles           []string              // Names of all profiles of the config file
+	nextProfile        string                // Profile to start over with once the program quits
+	paused             bool                  // Whether scraping is paused to freeze the chart
+	emptyScrapes       int                   // Consecutive scrapes that failed or found no series
+	retryAt            time.Time             // When the failed scrape is retried (zero unless backing off)
+	staleScrapes       int                   // Consecutive failed scrapes tolerated before the error is shown
+	maxSeries          int                   // Maximum number of series shown by default (0 for unlimited)
+	dupPolicy          dupPolicy             // Value of a series listed more than once in a scrape
+	exportPath         string                // Directory exports are written to (empty for the working directory)
+	rate               bool                  // Whether counters are plotted as per-second rate
+	showTable          bool                  // Whether the current values are shown as table instead of the chart
+	seriesLocked       bool                  // Whether newly discovered series are added hidden
+	referenceSeries    string                // Series all others are plotted as a percentage of (empty for absolute values)
+	remoteWriteURL     string                // Remote-write endpoint captured data is exported to (empty to write a file)
+	frameCursor        time.Time             // Scrape the chart is stepped back to (zero for the live view)
+	overview           timeserieslinechart.Model
 	termWidth          int
 	termHeight         int
 	seriesColors       []lipgloss.Color // Colors for different series
 	legendViewport     viewport.Model   // Viewport for scrolling legend entries
 	yRangeSet          bool             // Whether Y range has been initialized
+	yMin               *float64         // Fixed lower bound of the Y axis (nil to fit it to the values)
+	yMax               *float64         // Fixed upper bound of the Y axis (nil to fit it to the values)
+	logScale           bool             // Whether values are plotted on a log10 Y axis
+	unit               valueUnit        // Unit of the Y axis labels, auto picks it from the metric name
+	precision          int              // Decimal places of plain Y labels (adaptivePrecision to pick them by magnitude)
+	xRelative          bool             // Whether the time axis is labeled relative to the latest scrape
+	threshold          *float64         // Alert threshold marked on the chart and legend (nil for none)
+	thresholdOp        thresholdOp      // Whether values above or below the threshold exceed it
 }
 
-// fetchMetricCmd returns a command that fetches metrics
-func fetchMetricCmd(url, metricName string) tea.Cmd {
+// fetchMetricCmd returns a command that fetches metrics from all URLs
+func fetchMetricCmd(cfg fetchConfig, urls []string, metricName string, match metricMatch) tea.Cmd {
 	return func() tea.Msg {
-		samples, err := fetchAllMetricSeries(url, metricName)
-		return MetricsMsg{Samples: samples, Err: err}
+		diagnostics := &connDiagnostics{}
+		cfg.diagnostics = diagnostics
+		samples, err := fetchSources(cfg, urls, metricName, match)
+		return MetricsMsg{Samples: samples, Err: err, Diagnostics: diagnostics}
 	}
 }
 
 // fetchAllMetricsCmd returns a command that fetches all available metrics
-func fetchAllMetricsCmd(url string) tea.Cmd {
+func fetchAllMetricsCmd(cfg fetchConfig, url string) tea.Cmd {
+	return func() tea.Msg {
+		metrics, metadata, err := fetchMetricIndex(cfg, url)
+		return MetricsListMsg{Metrics: metrics, Metadata: metadata, Err: err}
+	}
+}
+
+// fetchMetadataCmd returns a command that fetches the metadata of all metrics
+func fetchMetadataCmd(cfg fetchConfig, url string) tea.Cmd {
 	return func() tea.Msg {
-		metrics, err := fetchAllMetrics(url)
-		return MetricsListMsg{Metrics: metrics, Err: err}
+		metadata, err := fetchAllMetadata(cfg, url)
+		return MetadataMsg{Metadata: metadata, Err: err}
 	}
 }
 
 // tickCmd returns a command that ticks at the specified interval
-func tickCmd(interval time.Duration) tea.Cmd {
+func tickCmd(interval time.Duration, gen int) tea.Cmd {
 	return tea.Tick(interval, func(t time.Time) tea.Msg {
-		return TickMsg(t)
+		return TickMsg{Time: t, Gen: gen}
 	})
 }
 
-// yLabelFormatter returns a label formatter that displays at least 2 decimal places for small values
-func yLabelFormatter() func(int, float64) string {
+// pointTimeFormat shows the full precision of a data point's time, unlike the axis labels
+const pointTimeFormat = "2006-01-02 15:04:05.000 MST"
+
+// formatPointTime formats the time of a single data point for inspection,
+// e.g. to line up a spike with log timestamps
+func formatPointTime(t time.Time) string {
+	return t.Format(pointTimeFormat)
+}
+
+// adaptivePrecision lets yLabelFormatter pick the decimal places by magnitude
+const adaptivePrecision = -1
+
+// yLabelFormatter returns a label formatter that displays at least 2 decimal places for small values,
+// or exactly precision decimal places unless it is adaptivePrecision
+func yLabelFormatter(precision int) func(int, float64) string {
+	if precision >= 0 {
+		return func(_ int, v float64) string {
+			return fmt.Sprintf("%.*f", precision, v)
+		}
+	}
 	return func(idx int, v float64) string {
 		if v == 0 {
 			return "0.00"
@@ -205,15 +477,103 @@ func yLabelFormatter() func(int, float64) string {
 	}
 }
 
-// redrawChart redraws the chart respecting series selection
-func (m *Model) redrawChart() {
-	// Clear all data from the chart
-	m.chart.ClearAllData()
-	m.chart.Clear()
-	m.chart.DrawXYAxisAndLabel()
+// isFinite reports whether a value is neither NaN nor ±Inf
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
 
-	// Rebuild chart with only checked series
-	seriesIdx := 0
+// yRangeFor returns a reasonable Y range for the given value bounds, leaving
+// a 10% margin on both sides
+func yRangeFor(minVal, maxVal float64) (float64, float64) {
+	minY := minVal - math.Abs(minVal)*0.1
+	maxY := maxVal + math.Abs(maxVal)*0.1
+
+	// Handle edge cases
+	if minY == maxY {
+		// All values are the same, create a small range around the value
+		if minVal == 0 {
+			minY = -1
+			maxY = 1
+		} else {
+			// Create a 10% range around the value
+			delta := math.Abs(minVal) * 0.1
+			minY = minVal - delta
+			maxY = maxVal + delta
+		}
+	}
+
+	return minY, maxY
+}
+
+// transformed reports whether plotted values differ from the raw samples,
+// in which case the chart has to be redrawn from history on every update
+func (m *Model) transformed() bool {
+	return m.cumulative || m.showAggregated || m.referenceSeries != "" || !m.frameCursor.IsZero() || m.rateActive() || m.logScale
+}
+
+// metricBases returns the names of the metrics on the chart: the base names of
+// its series, or the names given with --metric before any series arrived
+func (m *Model) metricBases() []string {
+	var bases []string
+	for _, series := range m.seriesList {
+		if base, _, _ := strings.Cut(series.name, "{"); !slices.Contains(bases, base) {
+			bases = append(bases, base)
+		}
+	}
+	if len(bases) == 0 {
+		return metricNames(m.metricName)
+	}
+	return bases
+}
+
+// seriesMeta returns the TYPE and HELP of the metric a series belongs to
+func (m *Model) seriesMeta(fullName string) MetricMeta {
+	base, _, _ := strings.Cut(fullName, "{")
+	meta, _ := lookupMeta(m.metadata, base)
+	return meta
+}
+
+// rateApplies reports whether a series is plotted as per-second rate, which
+// only applies to counters
+func (m *Model) rateApplies(fullName string) bool {
+	return m.rate && m.seriesMeta(fullName).Type == "counter"
+}
+
+// rateActive reports whether any metric on the chart is plotted as per-second rate
+func (m *Model) rateActive() bool {
+	for _, base := range m.metricBases() {
+		if m.rateApplies(base) {
+			return true
+		}
+	}
+	return false
+}
+
+// windowPoints returns the points of a series captured since the last reset
+func (m *Model) windowPoints(name string) []timeserieslinechart.TimePoint {
+	data := m.dataHistory[name]
+
+	if !m.windowStart.IsZero() {
+		start := 0
+		for start < len(data) && data[start].Time.Before(m.windowStart) {
+			start++
+		}
+		data = data[start:]
+	}
+	return data
+}
+
+// plotLine is a single line as it is drawn on the chart
+type plotLine struct {
+	name     string
+	colorIdx int
+	points   []timeserieslinechart.TimePoint
+}
+
+// plotLines returns the lines currently drawn on the chart, which are either
+// the checked series or their aggregation
+func (m *Model) plotLines() []plotLine {
+	var lines []plotLine
 	// Use seriesList to maintain consistent order and colors
 	for _, series := range m.seriesList {
 		// Check if this series is checked (visible)
@@ -222,32 +582,250 @@ func (m *Model) redrawChart() {
 		}
 
 		// Get data for this series
-		data, exists := m.dataHistory[series.name]
-		if !exists {
+		if _, exists := m.dataHistory[series.name]; !exists {
 			continue
 		}
 
+		points := m.windowPoints(series.name)
+
+		// Hide everything after the frame stepped back to
+		if !m.frameCursor.IsZero() {
+			end := len(points)
+			for end > 0 && points[end-1].Time.After(m.frameCursor) {
+				end--
+			}
+			points = points[:end]
+		}
+
+		if m.rateApplies(series.name) {
+			points = ratePerSecond(points)
+		}
+
+		lines = append(lines, plotLine{
+			name:     series.name,
+			colorIdx: series.colorIdx,
+			points:   points,
+		})
+	}
+
+	if m.showAggregated {
+		lines = aggregateLines(lines, m.aggregateOp, m.groupBy)
+	} else if m.referenceSeries != "" {
+		reference := m.windowPoints(m.referenceSeries)
+		if m.rateApplies(m.referenceSeries) {
+			reference = ratePerSecond(reference)
+		}
+		for i := range lines {
+			lines[i].points = relativeTo(lines[i].points, reference)
+		}
+	}
+
+	if m.cumulative {
+		for i := range lines {
+			lines[i].points = cumulativeSum(lines[i].points)
+		}
+	}
+
+	return lines
+}
+
+// fitYRange fits the Y range to the currently plotted points
+func (m *Model) fitYRange() {
+	minVal, maxVal, ok := m.plottedBounds()
+	if !ok {
+		return
+	}
+	m.setYRange(m.yBounds(minVal, maxVal))
+}
+
+// redrawChart redraws the chart respecting series selection. The Y range is
+// fitted to the series that are plotted now, which may span a smaller range
+// than the series hidden since.
+func (m *Model) redrawChart() {
+	m.fitYRange()
+
+	// Clear all data from the chart
+	m.chart.ClearAllData()
+	m.evictedScrapes = 0
+	m.chart.Clear()
+	m.chart.DrawXYAxisAndLabel()
+
+	// Rebuild chart with only checked series
+	for _, line := range m.chartLines() {
 		// Set style for all datasets (all use named datasets now)
-		colorIdx := series.colorIdx % len(m.seriesColors)
-		style := lipgloss.NewStyle().Foreground(m.seriesColors[colorIdx])
-		m.chart.SetDataSetStyle(series.name, style)
-		m.chart.SetDataSetLineStyle(series.name, runes.ThinLineStyle)
+		colorIdx := line.colorIdx % len(m.seriesColors)
+		m.styleSeries(line.name, m.seriesColors[colorIdx])
 
 		// Re-push all historical data points
-		for _, point := range data {
-			m.chart.PushDataSet(series.name, point)
+		for _, point := range line.points {
+			m.chart.PushDataSet(line.name, point)
 		}
-		seriesIdx++
 	}
 
 	// Draw the rebuilt chart
-	m.chart.DrawAll()
+	m.drawChart()
+}
+
+// seriesStyle returns the style of a series' line, emphasizing series matching --highlight
+func (m *Model) seriesStyle(name string, color lipgloss.Color) lipgloss.Style {
+	style := lipgloss.NewStyle().Foreground(color)
+	if m.highlight == nil {
+		return style
+	}
+	if m.highlight.MatchString(name) {
+		return style.Bold(true)
+	}
+	return style.Faint(true)
+}
+
+// styleSeries sets the style of a series' line on the chart
+func (m *Model) styleSeries(name string, color lipgloss.Color) {
+	m.chart.SetDataSetStyle(name, m.seriesStyle(name, color))
+	m.chart.SetDataSetLineStyle(name, runes.ThinLineStyle)
+}
+
+// drawChart draws all datasets and marks the parts beyond the threshold
+func (m *Model) drawChart() {
+	if m.showOverview {
+		m.redrawOverview()
+	}
+	m.drawLines()
+	m.thickenHighlighted()
+	m.markThreshold()
+	m.markAnnotations()
+}
+
+// drawLines draws all datasets, putting the largest series on top when sorting by value
+func (m *Model) drawLines() {
+	if !m.sortByValue {
+		m.chart.DrawAll()
+		return
+	}
+
+	// Lines are drawn over each other, so draw the largest one last
+	lines := m.plotLines()
+	sortLinesByValue(lines)
+	var names []string
+	for i := len(lines) - 1; i >= 0; i-- {
+		if len(lines[i].points) > 0 {
+			names = append(names, lines[i].name)
+		}
+	}
+	if len(names) == 0 {
+		m.chart.DrawAll()
+		return
+	}
+	m.chart.DrawDataSets(names)
+}
+
+// sortLinesByValue orders lines by their latest value, largest first and NaN last
+func sortLinesByValue(lines []plotLine) {
+	last := func(line plotLine) float64 {
+		if len(line.points) == 0 || math.IsNaN(line.points[len(line.points)-1].Value) {
+			return math.Inf(-1)
+		}
+		return line.points[len(line.points)-1].Value
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		return last(lines[i]) > last(lines[j])
+	})
+}
+
+// rankValues returns a function giving the latest plotted value of a series to
+// rank it by, e.g. its rate. Values that can't be plotted, like NaN, rank lowest.
+func (m *Model) rankValues() func(name string) float64 {
+	values := make(map[string]float64)
+	for _, line := range m.plotLines() {
+		if len(line.points) == 0 {
+			continue
+		}
+		if v, ok := m.chartValue(line.points[len(line.points)-1].Value); ok && !math.IsNaN(v) {
+			values[line.name] = v
+		}
+	}
+	return func(name string) float64 {
+		if v, ok := values[name]; ok {
+			return v
+		}
+		return math.Inf(-1)
+	}
+}
+
+// topSeries returns the n series (given as seriesList indices) with the
+// highest plotted values, keeping their seriesList order
+func (m *Model) topSeries(indices []int, n int) []int {
+	if len(indices) <= n {
+		return indices
+	}
+
+	rank := m.rankValues()
+	ranked := append([]int(nil), indices...)
+	sort.SliceStable(ranked, func(a, b int) bool {
+		return rank(m.seriesList[ranked[a]].name) > rank(m.seriesList[ranked[b]].name)
+	})
+	ranked = ranked[:n]
+	sort.Ints(ranked)
+	return ranked
+}
+
+// legendLabelMax returns the width of labels in a legend of the given width,
+// leaving room for the sparkline before and the trend arrow behind them
+func legendLabelMax(width, sparkPoints int, trends bool) int {
+	labelMax := width - 5
+	if trends {
+		labelMax -= 3
+	}
+	if sparkPoints > 0 {
+		labelMax -= sparkPoints + 1
+	}
+	return max(labelMax, minLegendLabel)
 }
 
 func (m *Model) rebuildLegend() {
 	legendContent := ""
 
+	trends := m.legendTrends()
+	stats := m.legendStats()
+	sparks := m.legendSparklines()
+	over := m.overThreshold()
+	labelMax := legendLabelMax(m.legendWidth, m.sparkPoints, trends != nil)
+
+	// Aggregated lines don't map to individual series, so list them as they are
+	if m.showAggregated {
+		lines := m.plotLines()
+		if m.sortByValue {
+			sortLinesByValue(lines)
+		}
+		for _, line := range lines {
+			color := m.seriesColors[line.colorIdx%len(m.seriesColors)]
+			indicator := seriesIndicator(color, line.colorIdx)
+
+			legendLabel := line.name
+			if len(legendLabel) > labelMax {
+				legendLabel = legendLabel[:labelMax-3] + "..."
+			}
+			if over[line.name] {
+				legendLabel = thresholdLegendStyle.Render(legendLabel)
+			}
+			if arrow := trends[line.name]; arrow != "" {
+				legendLabel += " " + arrow
+			}
+			if spark := sparks[line.name]; spark != "" {
+				legendLabel = spark + " " + legendLabel
+			}
+
+			legendContent += fmt.Sprintf("%s %s\n", indicator, legendLabel)
+			if text := stats[line.name]; text != "" {
+				legendContent += labelStyle.Render("  "+text) + "\n"
+			}
+		}
+
+		m.legendViewport.SetContent(legendContent)
+		return
+	}
+
 	// Iterate through seriesList to maintain consistent order
+	var entries []int
 	for i, series := range m.seriesList {
 		// Only show checked series
 		if !series.checked {
@@ -259,54 +837,184 @@ func (m *Model) rebuildLegend() {
 			continue
 		}
 
+		entries = append(entries, i)
+	}
+
+	// Only list the series with the highest values if the legend is capped
+	hidden := 0
+	if m.legendMax > 0 && len(entries) > m.legendMax {
+		hidden = len(entries) - m.legendMax
+		entries = m.topSeries(entries, m.legendMax)
+	}
+
+	if m.sortByValue {
+		rank := m.rankValues()
+		sort.SliceStable(entries, func(a, b int) bool {
+			return rank(m.seriesList[entries[a]].name) > rank(m.seriesList[entries[b]].name)
+		})
+	}
+
+	lastGroup := ""
+	mixed := m.mixedMetrics()
+	for _, i := range entries {
+		series := m.seriesList[i]
+
 		// Get color for this series
 		colorIdx := series.colorIdx % len(m.seriesColors)
 		color := m.seriesColors[colorIdx]
 
 		// Create colored indicator
-		indicator := lipgloss.NewStyle().Foreground(color).Render("■")
-
-		// Extract only the labels part (between curly braces)
-		legendLabel := series.name
-
-		// use metric name if no labels
-		if strings.HasSuffix(legendLabel, "{}") {
-			legendLabel = strings.TrimSuffix(legendLabel, "{}")
-		} else if idx := strings.Index(legendLabel, "{"); idx != -1 {
-			legendLabel = legendLabel[idx:]
+		indicator := seriesIndicator(color, colorIdx)
+
+		// Show only the label pairs, or the metric name if there are none or several metrics are shown
+		legendLabel := metricLabel(series.name, mixed)
+
+		// List the quantiles of a summary below a header naming it, unless sorting mixes them up
+		group, isQuantile := quantileGroup(series.name)
+		if isQuantile && !m.sortByValue {
+			if group != lastGroup {
+				header := labelPairs(group)
+				if base, _, _ := strings.Cut(group, "{"); header != base {
+					header = base + " " + header
+				}
+				if len(header) > labelMax+2 {
+					header = header[:labelMax-1] + "..."
+				}
+				legendContent += labelStyle.Render(header) + "\n"
+			}
+			q, _ := quantileOf(series.name)
+			legendLabel = formatQuantile(q)
 		}
+		lastGroup = group
 
 		// Add legend entry with truncation if too long
-		if len(legendLabel) > 30 {
-			legendLabel = legendLabel[:27] + "..."
+		if len(legendLabel) > labelMax {
+			legendLabel = legendLabel[:labelMax-3] + "..."
+		}
+		if over[series.name] {
+			legendLabel = thresholdLegendStyle.Render(legendLabel)
+		}
+		if arrow := trends[series.name]; arrow != "" {
+			legendLabel += " " + arrow
 		}
 
 		legendLabel = zone.Mark("series-"+fmt.Sprintf("%d", i), legendLabel)
+		if spark := sparks[series.name]; spark != "" {
+			legendLabel = spark + " " + legendLabel
+		}
 
 		legendContent += fmt.Sprintf("%s %s\n", indicator, legendLabel)
+		if text := stats[series.name]; text != "" {
+			legendContent += labelStyle.Render("  "+text) + "\n"
+		}
+	}
+
+	if hidden > 0 {
+		legendContent += labelStyle.Render(fmt.Sprintf("+%d more", hidden)) + "\n"
 	}
 
 	m.legendViewport.SetContent(legendContent)
 }
 
-func legendInnerDimensions(totalHeight int) (int, int) {
-	width := max(legendBoxWidth-2-2*legendContentPad, 1)
+// soloStep shows only the next (delta > 0) or previous (delta < 0) series out of
+// the ones that were visible when solo-stepping started
+func (m *Model) soloStep(delta int) {
+	if m.soloVisibility == nil {
+		m.soloVisibility = make(map[string]bool, len(m.seriesList))
+		for _, s := range m.seriesList {
+			m.soloVisibility[s.name] = s.checked
+		}
+		m.soloIndex = -1
+		if delta < 0 {
+			m.soloIndex = 0
+		}
+	}
+
+	var visible []int
+	for i, s := range m.seriesList {
+		if m.soloVisibility[s.name] {
+			visible = append(visible, i)
+		}
+	}
+	if len(visible) == 0 {
+		m.soloVisibility = nil
+		return
+	}
+
+	m.soloIndex = ((m.soloIndex+delta)%len(visible) + len(visible)) % len(visible)
+	for i := range m.seriesList {
+		m.seriesList[i].checked = i == visible[m.soloIndex]
+	}
+
+	m.redrawChart()
+	m.rebuildLegend()
+}
+
+// setSeriesChecked shows or hides a series. Series that vanish stay listed,
+// so the choice also holds when the series comes back.
+func (m *Model) setSeriesChecked(i int, checked bool) {
+	m.seriesList[i].checked = checked
+}
+
+// exitSolo restores the visibility from before solo-stepping started
+func (m *Model) exitSolo() {
+	if m.soloVisibility == nil {
+		return
+	}
+	for i, s := range m.seriesList {
+		m.seriesList[i].checked = m.soloVisibility[s.name]
+	}
+	m.soloVisibility = nil
+
+	m.redrawChart()
+	m.rebuildLegend()
+}
+
+func legendInnerDimensions(boxWidth, totalHeight int) (int, int) {
+	width := max(boxWidth-2-2*legendContentPad, 1)
 	height := max(totalHeight-4, 1)
 	return width, height
 }
 
 func newLegendViewport(totalHeight int) viewport.Model {
-	width, height := legendInnerDimensions(totalHeight)
+	width, height := legendInnerDimensions(legendBoxWidth, totalHeight)
 	viewportModel := viewport.New(width, height)
 	viewportModel.MouseWheelEnabled = true
+	// Drop the scroll keys that clash with the chart keybindings
+	viewportModel.KeyMap.PageDown.SetKeys("pgdown", " ")
+	viewportModel.KeyMap.PageUp.SetKeys("pgup")
+	viewportModel.KeyMap.Left.SetKeys("left")
+	viewportModel.KeyMap.Right.SetKeys("right")
 	return viewportModel
 }
 
+// chartBorderSize returns the number of rows/columns used by the chart border
+func (m *Model) chartBorderSize() int {
+	if m.hideBorder {
+		return 0
+	}
+	return 2
+}
+
+// headerHeight returns the lines of the header, which is as high as the logo
+// and its trailing newline, or just the title and subtitle without it
+func (m *Model) headerHeight() int {
+	if m.hideLogo {
+		return 2
+	}
+	return 5
+}
+
+// legendHeight returns the legend box height matching the outer chart height
+func (m *Model) legendHeight() int {
+	return m.height + m.chartBorderSize() - 2
+}
+
 func (m *Model) updateLegendViewportSize() {
 	if !m.showLegend {
 		return
 	}
-	width, height := legendInnerDimensions(m.height)
+	width, height := legendInnerDimensions(m.legendWidth, m.legendHeight())
 	m.legendViewport.Width = width
 	m.legendViewport.Height = height
 }
@@ -323,7 +1031,7 @@ func NewModel(url, metricName string, interval time.Duration) Model {
 		timeserieslinechart.WithLineStyle(runes.ThinLineStyle),
 		timeserieslinechart.WithUpdateHandler(timeserieslinechart.SecondUpdateHandler(int(interval.Seconds()))),
 		timeserieslinechart.WithXLabelFormatter(timeserieslinechart.HourTimeLabelFormatter()),
-		timeserieslinechart.WithYLabelFormatter(yLabelFormatter()),
+		timeserieslinechart.WithYLabelFormatter(yLabelFormatter(adaptivePrecision)),
 	)
 
 	l := list.New([]list.Item{}, metricDelegate{}, 50, 20)
@@ -333,60 +1041,173 @@ func NewModel(url, metricName string, interval time.Duration) Model {
 	l.Styles.Title = listTitleStyle
 
 	return Model{
-		url:         url,
-		metricName:  metricName,
-		interval:    interval,
-		chart:       chart,
-		width:       width,
-		height:      height,
-		selectMode:  false,
-		metricsList: l,
-		termWidth:   0,
-		termHeight:  0,
-		lastValues:  make(map[string]float64),
-		dataHistory: make(map[string][]timeserieslinechart.TimePoint),
-		seriesColors: []lipgloss.Color{
-			"#ff5f00", "46", "226", "201", "51", "208", "99", "171",
-			"196", "33", "214", "40", "129", "39", "160", "45",
-			"220", "135", "118", "200", "81", "227", "161", "48",
-			"57", "190", "213", "38", "154", "124", "27", "141",
-		},
+		url:            url,
+		urls:           []string{url},
+		metricName:     metricName,
+		interval:       interval,
+		chart:          chart,
+		overview:       newOverviewChart(width),
+		width:          width,
+		height:         height,
+		selectMode:     false,
+		metricsList:    l,
+		termWidth:      0,
+		termHeight:     0,
+		lastValues:     make(map[string]float64),
+		dataHistory:    make(map[string][]timeserieslinechart.TimePoint),
+		seriesColors:   seriesPalette,
 		legendViewport: newLegendViewport(height),
+		legendWidth:    legendBoxWidth,
 		yRangeSet:      false,
 		hoveredSeries:  -1,
+		aggregateOp:    "sum",
+		precision:      adaptivePrecision,
+		spinner:        newFetchSpinner(),
+		fetching:       true, // Init starts with a scrape
 	}
 }
 
+// loadCmd returns a command that loads the data and metadata of the current metric
+func (m *Model) loadCmd() tea.Cmd {
+	if m.replayFrames != nil {
+		return tea.Batch(
+			replayCmd(m.replayFrames, m.metricName, m.metricMatch),
+			replayMetadataCmd(m.replayFrames),
+		)
+	}
+	// Query results come without metadata
+	if m.fetch.query != "" {
+		return m.startFetch(fetchMetricCmd(m.fetch, m.urls, m.metricName, m.metricMatch))
+	}
+	return tea.Batch(
+		m.startFetch(fetchMetricCmd(m.fetch, m.urls, m.metricName, m.metricMatch)),
+		fetchMetadataCmd(m.fetch, m.url),
+	)
+}
+
+// switchMetric starts over with another metric, dropping everything captured
+// of the current one. The caller loads the new metric.
+func (m *Model) switchMetric(name string) {
+	m.metricName = name
+	// Names picked from the list are exact, unlike a --metric pattern
+	m.metricMatch = matchExact
+
+	// Recreate chart to clear all dataset configurations
+	m.chart = timeserieslinechart.New(m.width, m.height,
+		timeserieslinechart.WithAxesStyles(axisStyle, labelStyle),
+		timeserieslinechart.WithStyle(graphStyle),
+		timeserieslinechart.WithLineStyle(runes.ThinLineStyle),
+		timeserieslinechart.WithUpdateHandler(timeserieslinechart.SecondUpdateHandler(int(m.interval.Seconds()))),
+		timeserieslinechart.WithXLabelFormatter(timeserieslinechart.HourTimeLabelFormatter()),
+		timeserieslinechart.WithYLabelFormatter(m.yFormatter()),
+	)
+	m.applyXScale()
+	m.applyYScale()
+	m.chart.DrawXYAxisAndLabel()
+
+	m.err = nil
+	m.emptyScrapes = 0
+	m.retryAt = time.Time{}
+	m.lastValues = make(map[string]float64)
+	m.dataHistory = make(map[string][]timeserieslinechart.TimePoint)
+	m.lastUpdate = time.Time{}
+	m.yRangeSet = false
+	m.seriesList = nil
+	// --series-filter applies to the series of the metric picked on startup
+	m.seriesMatch = nil
+	m.seriesListSelected = 0
+	m.seriesListScroll = 0
+	m.soloVisibility = nil
+	m.windowStart = time.Time{}
+	m.infoSamples = nil
+	m.correlated = nil
+	m.referenceSeries = ""
+	m.frameCursor = time.Time{}
+}
+
+// listMetricsCmd returns a command that lists all available metrics
+func (m *Model) listMetricsCmd() tea.Cmd {
+	if m.replayFrames != nil {
+		return replayMetricsCmd(m.replayFrames)
+	}
+	return fetchAllMetricsCmd(m.fetch, m.url)
+}
+
 func (m Model) Init() tea.Cmd {
 	m.chart.DrawXYAxisAndLabel()
+	// Replays are loaded at once and don't poll
+	if m.replayFrames != nil {
+		return m.loadCmd()
+	}
 	// Start by fetching metrics immediately and setting up tick
 	return tea.Batch(
-		fetchMetricCmd(m.url, m.metricName),
-		tickCmd(m.interval),
+		m.loadCmd(),
+		tickCmd(m.interval, m.tickGen),
 	)
 }
 
+// infoView renders the TYPE and HELP of the metrics on the chart, wrapped to the
+// terminal width. Overlaid metrics get a line each.
+func (m *Model) infoView() string {
+	bases := m.metricBases()
+	if len(bases) == 0 {
+		bases = []string{m.metricName}
+	}
+
+	lines := make([]string, 0, len(bases))
+	for _, base := range bases {
+		meta, ok := lookupMeta(m.metadata, base)
+		if !ok {
+			meta = MetricMeta{Type: "unknown", Help: "no help text available"}
+		}
+		if meta.Type == "" {
+			meta.Type = "unknown"
+		}
+
+		line := fmt.Sprintf("TYPE: %s | HELP: %s", meta.Type, meta.Help)
+		if len(bases) > 1 {
+			line = base + " " + line
+		}
+		lines = append(lines, line)
+	}
+	return labelStyle.Width(max(m.termWidth-4, 1)).MarginLeft(2).Render(strings.Join(lines, "\n"))
+}
+
 // resizeChart resizes the chart based on terminal dimensions
 func (m *Model) resizeChart() {
 	if m.termWidth == 0 || m.termHeight == 0 {
 		return
 	}
 
-	headerFooterHeight := 9
+	headerFooterHeight := m.headerHeight() + 2 + m.chartBorderSize()
+	if m.fullscreen {
+		headerFooterHeight = m.chartBorderSize()
+	}
 	if m.err != nil {
 		headerFooterHeight += 2
 	}
+	if m.showInfo {
+		headerFooterHeight += lipgloss.Height(m.infoView())
+	}
+	if m.showOverview {
+		headerFooterHeight += overviewHeight
+	}
 
 	// Calculate chart dimensions
-	chartWidth := m.termWidth - 6 // Account for borders and padding
+	chartWidth := m.termWidth - 4 - m.chartBorderSize() // Account for borders and padding
 
 	// If legend is shown, reduce chart width to make room for it
 	if m.showLegend {
-		chartWidth -= 38 // Legend width (35) + spacing (3)
+		chartWidth -= m.legendWidth + 3 // Legend width + spacing
 	}
 
 	chartHeight := m.termHeight - headerFooterHeight
 
+	// Keep a readable aspect ratio on wide terminals
+	if m.capWidth && chartWidth > chartHeight*maxChartAspect {
+		chartWidth = chartHeight * maxChartAspect
+	}
+
 	// Ensure minimum size
 	if chartWidth < 40 {
 		chartWidth = 40
@@ -409,10 +1230,11 @@ func (m *Model) resizeChart() {
 		if len(m.dataHistory) <= 1 {
 			m.chart.Draw()
 		} else {
-			m.chart.DrawAll()
+			m.drawChart()
 		}
 	}
 
+	m.overview.Resize(m.width+m.chartBorderSize(), overviewHeight-1)
 	m.updateLegendViewportSize()
 }
 
@@ -425,19 +1247,97 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// Handle TickMsg and MetricsMsg regardless of mode to keep scraping active
 	switch msg := msg.(type) {
 	case TickMsg:
+		// A changed interval started ticking anew
+		if msg.Gen != m.tickGen {
+			return m, nil
+		}
+		// Keep ticking while paused so resuming doesn't need to restart the tick
+		if m.paused {
+			return m, tickCmd(m.interval, m.tickGen)
+		}
 		// Fetch new metrics and schedule next tick
 		return m, tea.Batch(
-			fetchMetricCmd(m.url, m.metricName),
-			tickCmd(m.interval),
+			m.startFetch(fetchMetricCmd(m.fetch, m.urls, m.metricName, m.metricMatch)),
+			tickCmd(m.interval, m.tickGen),
 		)
-	case MetricsMsg:
+	case spinner.TickMsg:
+		// The spinner stops once the scrape is done
+		if !m.fetching {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+	case SearchIndexMsg:
+		m.searchIndex, m.searchErr = msg.Samples, msg.Err
+		return m, nil
+	case MetadataMsg:
+		// Metadata is optional, so errors are ignored
+		if msg.Err == nil {
+			m.metadata = msg.Metadata
+			m.resizeChart()
+			// The samples may have been plotted before the TYPE revealed an info metric
+			if samples := m.latestSamples(); !m.includeInfo && m.infoSamples == nil && len(samples) > 0 && m.isInfo(samples) {
+				m.listAsInfo(samples)
+				return m, nil
+			}
+			// The metric may only now turn out to be a counter to plot as rate
+			if m.rateActive() {
+				m.redrawChart()
+			}
+		}
+		return m, nil
+	case RemoteWriteMsg:
+		if msg.Err != nil {
+			m.status = fmt.Sprintf("Export to %s failed: %v", msg.Target, msg.Err)
+		} else {
+			m.status = "Exported captured data to " + msg.Target
+		}
+		return m, nil
+	case CSVExportMsg:
 		if msg.Err != nil {
 			m.err = msg.Err
-			return m, nil
+		} else {
+			m.status = "Exported visible series to " + msg.Path
 		}
+		return m, nil
 
-		m.err = nil
-		m.lastUpdate = time.Now()
+	case MetricsMsg:
+		m.fetching = false
+		if msg.Diagnostics != nil {
+			m.diagnostics = msg.Diagnostics
+		}
+		// Scrapes of several URLs still plot the series of the URLs that responded
+		if msg.Err != nil && len(msg.Samples) == 0 {
+			// Keep showing the last chart through brief outages, e.g. a restart of the target
+			m.emptyScrapes++
+			if len(m.dataHistory) == 0 || m.emptyScrapes > m.staleScrapes {
+				m.err = msg.Err
+			}
+			return m, m.scheduleRetry()
+		}
+
+		m.emptyScrapes = 0
+		m.retryAt = time.Time{}
+		m.err = msg.Err
+		firstScrape := m.lastUpdate.IsZero()
+		m.lastUpdate = msg.Time
+		if m.lastUpdate.IsZero() {
+			m.lastUpdate = time.Now()
+		}
+		// Relative time labels count back from the latest scrape
+		if m.xRelative {
+			m.applyXScale()
+		}
+
+		// Start the time axis at the first scrape, which may lie in the past for replays
+		if firstScrape {
+			m.chart.SetTimeRange(m.lastUpdate, m.lastUpdate.Add(time.Second))
+			m.chart.SetViewTimeRange(m.lastUpdate, m.lastUpdate.Add(time.Second))
+		}
+		// A zoomed or scrolled view stays put while new scrapes extend the time range
+		pinned := !firstScrape && !m.following()
+		viewMin, viewMax := m.chart.ViewMinX(), m.chart.ViewMaxX()
 
 		// Validate that samples belong to the current metric
 		// Extract base name from first sample to check
@@ -448,13 +1348,29 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				baseName = firstSample[:idx]
 			}
 			// Ignore messages for the wrong metric (can happen when switching metrics)
-			if baseName != m.metricName {
+			if matches, err := m.metricMatch.matcher(m.metricName); err != nil || !matches(baseName) {
 				return m, nil
 			}
 		}
 
+		// Info metrics are flat lines at 1, so list their labels instead
+		if !m.includeInfo && m.isInfo(msg.Samples) {
+			m.infoSamples = msg.Samples
+			return m, nil
+		}
+		m.infoSamples = nil
+
+		// Buggy exporters may list a series twice, which would plot two points at once
+		msg.Samples = dedupeSamples(msg.Samples, m.dupPolicy)
+
+		// Collapse series that only differ in labels outside of --by
+		if len(m.seriesBy) > 0 {
+			msg.Samples = collapseSeries(msg.Samples, m.seriesBy)
+		}
+
 		// Update series list when new samples arrive
 		newSeriesAdded := false
+		added := make(map[string]float64) // Values of new series shown by default
 		if len(msg.Samples) > 0 {
 			// Check if we need to add new series to the list
 			existingSeries := make(map[string]bool)
@@ -465,10 +1381,23 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			for _, sample := range msg.Samples {
 				displayName := sample.FullName
 				if !existingSeries[displayName] {
+					// Keep new series hidden while the series set is locked or they don't
+					// match --series-filter. Series that vanish stay listed, so one that
+					// comes back keeps the visibility it had.
+					visible := !m.seriesLocked && (m.seriesMatch == nil || m.seriesMatch.MatchString(displayName))
+					if visible {
+						added[displayName] = sample.Value
+					}
+
+					// Keep new series hidden while solo-stepping, but show them afterwards
+					if m.soloVisibility != nil {
+						m.soloVisibility[displayName] = visible
+					}
+
 					// Use the current length of seriesList as the colorIdx to ensure each series gets a unique color
 					m.seriesList = append(m.seriesList, seriesItem{
 						name:     displayName,
-						checked:  true,
+						checked:  m.soloVisibility == nil && visible,
 						colorIdx: len(m.seriesList),
 					})
 					newSeriesAdded = true
@@ -477,46 +1406,51 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
-		// Update Y range dynamically if needed (based on first sample)
-		if len(msg.Samples) > 0 && !m.yRangeSet {
-			// Initial setup - set a reasonable range based on all values
-			minVal := msg.Samples[0].Value
-			maxVal := msg.Samples[0].Value
+		// Keep summary quantiles in ascending order and give every series a color of its own
+		if newSeriesAdded {
+			sortSeriesByQuantile(m.seriesList)
+			m.seriesColors = extendPalette(m.seriesColors, len(m.seriesList))
+		}
+
+		// Only draw the top series of a metric with many of them by default
+		capNewSeries(m.seriesList, added, m.maxSeries)
+
+		// Update Y range dynamically if needed (based on first sample).
+		// Rates need two scrapes and log scaled values differ from the samples,
+		// so their range is fitted below once they are plotted.
+		if len(msg.Samples) > 0 && !m.yRangeSet && !m.rateActive() && !m.logScale {
+			// Initial setup - set a reasonable range based on all finite values
+			found := false
+			var minVal, maxVal float64
 			for _, sample := range msg.Samples {
-				if sample.Value < minVal {
+				if !isFinite(sample.Value) {
+					continue
+				}
+				if !found || sample.Value < minVal {
 					minVal = sample.Value
 				}
-				if sample.Value > maxVal {
+				if !found || sample.Value > maxVal {
 					maxVal = sample.Value
 				}
+				found = true
 			}
 
-			minY := minVal * 0.9
-			maxY := maxVal * 1.1
-
-			// Handle edge cases
-			if minY == maxY {
-				// All values are the same, create a small range around the value
-				if minVal == 0 {
-					minY = -1
-					maxY = 1
-				} else {
-					// Create a 10% range around the value
-					delta := math.Abs(minVal) * 0.1
-					minY = minVal - delta
-					maxY = maxVal + delta
-				}
+			if found {
+				m.setYRange(m.yBounds(minVal, maxVal))
 			}
-
-			m.chart.SetYRange(minY, maxY)
-			m.chart.SetViewYRange(minY, maxY)
-			m.yRangeSet = true
 		}
 
 		// Process each sample and push to appropriate dataset
+		evicted := false
 		for i, sample := range msg.Samples {
 			m.lastValues[sample.FullName] = sample.Value
 
+			// NaN and ±Inf are valid sample values, e.g. of quantiles without
+			// observations, but can't be plotted
+			if !isFinite(sample.Value) {
+				continue
+			}
+
 			point := timeserieslinechart.TimePoint{
 				Time:  m.lastUpdate,
 				Value: sample.Value,
@@ -534,40 +1468,142 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					if s.name == displayName {
 						isChecked = s.checked
 
-						// properly get color based on hover state
-						if m.hoveredSeries == -1 || m.hoveredSeries == i {
-							color = m.seriesColors[s.colorIdx%len(m.seriesColors)]
-						} else {
-							color = lipgloss.Color("#808080")
-						}
+						// properly get color based on hover state
+						if m.hoveredSeries == -1 || m.hoveredSeries == i {
+							color = m.seriesColors[s.colorIdx%len(m.seriesColors)]
+						} else {
+							color = lipgloss.Color("#808080")
+						}
+
+						break
+					}
+				}
+			}
+
+			datasetName := displayName
+			var dropped bool
+			m.dataHistory[datasetName], dropped = appendHistory(m.dataHistory[datasetName], point, m.maxPoints)
+			evicted = evicted || dropped
+
+			// Set style for this dataset
+			m.styleSeries(datasetName, color)
+
+			if isChecked && !m.transformed() {
+				m.chart.PushDataSet(datasetName, point)
+			}
+		}
+
+		if !m.yRangeSet && (m.rateActive() || m.logScale) {
+			m.fitYRange()
+		}
+		if pinned {
+			m.chart.SetViewXRange(viewMin, viewMax)
+		}
+
+		// Transformed values depend on the whole history, so re-plot everything.
+		// Dropped points only leave the chart once it's rebuilt.
+		if (evicted && m.evictHistory()) || m.transformed() {
+			m.redrawChart()
+		}
+		m.expandYRange()
+
+		// rebuild after adding history data, or whenever the ranking of a capped legend, the trends, the stats,
+		// the sparklines or the series beyond the threshold may change
+		if newSeriesAdded || m.legendMax > 0 || m.trendWindow > 0 || m.showStats || m.sparkPoints > 0 || m.threshold != nil {
+			m.rebuildLegend()
+		}
+
+		// Draw the chart (only if not in series selection mode)
+		// Always draw all datasets since all series now use named datasets
+		if !m.seriesSelectMode {
+			m.drawChart()
+		}
+		return m, nil
+	}
+
+	// If the help overlay is shown, only handle closing it
+	if m.showHelp {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			switch msg.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "?", "q", "esc":
+				m.showHelp = false
+			}
+		}
+		return m, nil
+	}
 
-						break
-					}
-				}
+	// If the global search is shown, let it handle all keys
+	if m.searching {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			if msg.String() == "ctrl+c" {
+				return m, tea.Quit
 			}
+			return m, m.updateSearch(msg)
+		}
+		return m, nil
+	}
 
-			datasetName := displayName
-			m.dataHistory[datasetName] = append(m.dataHistory[datasetName], point)
+	// While typing the label of an annotation, let it handle all keys
+	if m.annotating {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			if msg.String() == "ctrl+c" {
+				return m, tea.Quit
+			}
+			m.updateAnnotationLabel(msg)
+		}
+		return m, nil
+	}
 
-			// Set style for this dataset
-			style := lipgloss.NewStyle().Foreground(color)
-			m.chart.SetDataSetStyle(datasetName, style)
-			m.chart.SetDataSetLineStyle(datasetName, runes.ThinLineStyle)
+	// If the diagnostics overlay is shown, only handle closing it
+	if m.showDiagnostics {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			switch msg.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "D", "q", "esc":
+				m.showDiagnostics = false
+			}
+		}
+		return m, nil
+	}
 
-			if isChecked {
-				m.chart.PushDataSet(datasetName, point)
+	// If the histogram overlay is shown, only handle closing it
+	if m.histogramSeries != "" {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			switch msg.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "h", "q", "esc":
+				m.histogramSeries = ""
 			}
 		}
+		return m, nil
+	}
 
-		// rebuild after adding history data
-		if newSeriesAdded {
-			m.rebuildLegend()
+	// If the bucket distribution is shown, only handle closing it
+	if m.showBuckets {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			switch msg.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "B", "q", "esc":
+				m.showBuckets = false
+			}
 		}
+		return m, nil
+	}
 
-		// Draw the chart (only if not in series selection mode)
-		// Always use DrawAll() since all series now use named datasets
-		if !m.seriesSelectMode {
-			m.chart.DrawAll()
+	// If the correlation overlay is shown, only handle closing it
+	if m.showCorrelation {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			switch msg.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "x", "q", "esc":
+				m.showCorrelation = false
+			}
 		}
 		return m, nil
 	}
@@ -576,9 +1612,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if m.seriesSelectMode {
 		switch msg := msg.(type) {
 		case tea.KeyMsg:
-			switch msg.String() {
-			case "ctrl+c":
+			m.status = ""
+			if msg.String() == "ctrl+c" {
 				return m, tea.Quit
+			}
+			if m.seriesFiltering {
+				m.updateSeriesFilter(msg)
+				return m, nil
+			}
+			switch msg.String() {
+			case "/":
+				m.seriesFiltering = true
+				return m, nil
 			case "q", "esc":
 				// Exit series selection mode without applying changes
 				m.seriesSelectMode = false
@@ -586,28 +1631,68 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "enter":
 				// Accept selection and exit series selection mode
 				m.seriesSelectMode = false
+				// A manual selection replaces whatever solo-stepping was hiding
+				m.soloVisibility = nil
 				// Redraw chart with updated series visibility
 				m.redrawChart()
 				m.rebuildLegend()
 				return m, nil
 			case " ":
 				// Toggle selected item
-				if len(m.seriesList) > 0 && m.seriesListSelected < len(m.seriesList) {
-					m.seriesList[m.seriesListSelected].checked = !m.seriesList[m.seriesListSelected].checked
+				if i, ok := m.selectedSeries(); ok {
+					m.setSeriesChecked(i, !m.seriesList[i].checked)
 				}
 				return m, nil
+			case "y":
+				// Copy the current value of the selected item
+				if i, ok := m.selectedSeries(); ok {
+					m.copyValue(m.seriesList[i].name)
+				}
+				return m, nil
+			case "r":
+				// Plot all series relative to the selected item, or back to absolute values
+				if i, ok := m.selectedSeries(); ok {
+					name := m.seriesList[i].name
+					if m.referenceSeries == name {
+						m.referenceSeries = ""
+					} else {
+						m.referenceSeries = name
+					}
+					m.redrawChart()
+					m.rebuildLegend()
+				}
+				return m, nil
+			case "h":
+				// Show the value histogram of the selected item
+				if i, ok := m.selectedSeries(); ok {
+					m.histogramSeries = m.seriesList[i].name
+				}
+				return m, nil
+			case "x":
+				// Mark the selected item for correlation
+				if i, ok := m.selectedSeries(); ok {
+					m.toggleCorrelated(m.seriesList[i].name)
+				}
+				return m, nil
+			case "n":
+				m.sortSeriesList(seriesSortName)
+				return m, nil
+			case "v":
+				m.sortSeriesList(seriesSortValue)
+				return m, nil
 			case "a":
-				// Toggle select/unselect all
+				// Toggle select/unselect all listed items
+				indices := m.filteredSeries()
 				allChecked := true
-				for _, s := range m.seriesList {
-					if !s.checked {
+				for _, i := range indices {
+					if !m.seriesList[i].checked {
 						allChecked = false
 						break
 					}
 				}
 				// If all checked, uncheck all; otherwise check all
-				for i := range m.seriesList {
-					m.seriesList[i].checked = !allChecked
+				for _, i := range indices {
+					m.setSeriesChecked(i, !allChecked)
 				}
 				return m, nil
 			case "up":
@@ -620,7 +1705,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 				return m, nil
 			case "down":
-				if m.seriesListSelected < len(m.seriesList)-1 {
+				if m.seriesListSelected < len(m.filteredSeries())-1 {
 					m.seriesListSelected++
 					// Adjust scroll if needed
 					maxVisible := max(m.termHeight-12, 3)
@@ -641,36 +1726,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			switch msg.String() {
 			case "enter":
 				// Switch to selected metric
-				i, ok := m.metricsList.SelectedItem().(metricItem)
-				if ok {
-					m.metricName = string(i)
-
-					// Recreate chart to clear all dataset configurations
-					m.chart = timeserieslinechart.New(m.width, m.height,
-						timeserieslinechart.WithAxesStyles(axisStyle, labelStyle),
-						timeserieslinechart.WithStyle(graphStyle),
-						timeserieslinechart.WithLineStyle(runes.ThinLineStyle),
-						timeserieslinechart.WithUpdateHandler(timeserieslinechart.SecondUpdateHandler(int(m.interval.Seconds()))),
-						timeserieslinechart.WithXLabelFormatter(timeserieslinechart.HourTimeLabelFormatter()),
-						timeserieslinechart.WithYLabelFormatter(yLabelFormatter()),
-					)
-					m.chart.DrawXYAxisAndLabel()
-
-					m.err = nil
-					m.lastValues = make(map[string]float64)
-					m.dataHistory = make(map[string][]timeserieslinechart.TimePoint)
-					m.lastUpdate = time.Time{}
-					m.yRangeSet = false
-					m.seriesList = nil
-					m.seriesListSelected = 0
-					m.seriesListScroll = 0
+				if i, ok := m.metricsList.SelectedItem().(metricItem); ok {
+					m.switchMetric(string(i))
 				}
 				m.metricsList.ResetFilter()
 				m.selectMode = false
-				return m, tea.Batch(
-					fetchMetricCmd(m.url, m.metricName),
-					tickCmd(m.interval),
-				)
+				// The running tick keeps polling, so only load the new metric once
+				return m, m.loadCmd()
 			case "ctrl+c":
 				// Always allow ctrl+c to quit
 				return m, tea.Quit
@@ -690,9 +1752,17 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 
+			// Show the HELP text of the listed metrics below their names
+			if msg.Metadata != nil {
+				m.metadata = msg.Metadata
+			}
+			m.metricsList.SetDelegate(metricDelegate{metadata: m.metadata})
+
 			// Populate the list with metrics
-			items := make([]list.Item, len(msg.Metrics))
-			for i, metric := range msg.Metrics {
+			metrics, hidden := limitMetrics(msg.Metrics, m.metricFilter, m.maxMetrics)
+			m.hiddenMetrics = hidden
+			items := make([]list.Item, len(metrics))
+			for i, metric := range metrics {
 				items[i] = metricItem(metric)
 			}
 			m.metricsList.SetItems(items)
@@ -707,13 +1777,25 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// Normal mode message handling
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		m.status = ""
 		switch msg.String() {
 		case "q", "ctrl+c":
 			return m, tea.Quit
+		case "/":
+			if m.replayFrames != nil || m.fetch.query != "" {
+				m.status = "Search is unavailable with --query and while replaying"
+				return m, nil
+			}
+			// Search the series of all metrics, e.g. for a label value
+			return m, m.openSearch()
 		case "m":
+			if m.fetch.query != "" {
+				m.status = "Metric selection is unavailable with --query"
+				return m, nil
+			}
 			// Enter metric select mode - fetch metrics first
 			m.selectMode = true
-			return m, fetchAllMetricsCmd(m.url)
+			return m, m.listMetricsCmd()
 		case "l":
 			// Rebuild legend before toggling
 			m.rebuildLegend()
@@ -727,15 +1809,236 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.seriesSelectMode = true
 				m.seriesListSelected = 0
 				m.seriesListScroll = 0
+				m.seriesFilter = ""
+				m.seriesFiltering = false
+			}
+		case "n", "N":
+			// Step through the visible series one at a time
+			delta := 1
+			if msg.String() == "N" {
+				delta = -1
+			}
+			m.soloStep(delta)
+		case "esc":
+			// Leave solo-stepping and restore the previous visibility
+			m.exitSolo()
+		case "b":
+			// Toggle the chart border
+			m.hideBorder = !m.hideBorder
+			m.resizeChart()
+		case "z":
+			// Toggle the fullscreen chart, giving the header and help bar's lines to the chart
+			m.fullscreen = !m.fullscreen
+			m.resizeChart()
+		case "c":
+			// Toggle plotting the running total of each series
+			m.cumulative = !m.cumulative
+			m.redrawChart()
+		case "t":
+			// Switch between the chart and a table of current values
+			m.showTable = !m.showTable
+		case "X":
+			// Toggle between wall-clock and relative time labels
+			m.xRelative = !m.xRelative
+			m.applyXScale()
+			m.drawChart()
+		case "g":
+			// Toggle the log10 scale of the Y axis
+			m.logScale = !m.logScale
+			m.applyYScale()
+			m.redrawChart()
+		case "R":
+			// Toggle plotting counters as per-second rate
+			m.rate = !m.rate
+			if m.rate && !m.rateActive() {
+				m.status = "Rate only applies to counters, it is used once a counter is shown"
+			}
+			m.redrawChart()
+		case "a":
+			// Toggle between the individual series and their aggregation
+			m.showAggregated = !m.showAggregated
+			m.redrawChart()
+			m.rebuildLegend()
+		case "A":
+			// Cycle through the aggregations, showing the aggregated view if it is hidden
+			if m.showAggregated {
+				m.aggregateOp = nextAggregateOp(m.aggregateOp)
+			}
+			m.showAggregated = true
+			m.status = "Aggregating series by " + m.aggregateOp
+			m.redrawChart()
+			m.rebuildLegend()
+		case "v":
+			// Toggle the full history overview below the chart
+			m.showOverview = !m.showOverview
+			m.resizeChart()
+			m.drawChart()
+		case "w":
+			// Toggle between filling the terminal width and a capped width
+			m.capWidth = !m.capWidth
+			m.resizeChart()
+		case "S":
+			// Toggle the min, max and average below each legend entry
+			m.showStats = !m.showStats
+			m.rebuildLegend()
+		case "o":
+			// Toggle ordering the legend by current value
+			m.sortByValue = !m.sortByValue
+			m.rebuildLegend()
+			m.drawChart()
+		case "?":
+			// Show all keybindings
+			m.showHelp = true
+		case "W":
+			// Export all captured data in the remote-write format
+			history := make(map[string][]timeserieslinechart.TimePoint, len(m.dataHistory))
+			for name := range m.dataHistory {
+				history[name] = m.windowPoints(name)
+			}
+			m.status = "Exporting captured data..."
+			return m, exportRemoteWriteCmd(m.fetch, history, m.metricName, m.remoteWriteURL, m.exportPath)
+		case "e":
+			// Export the captured data of the visible series as CSV
+			history := make(map[string][]timeserieslinechart.TimePoint, len(m.dataHistory))
+			var names []string
+			for _, series := range m.seriesList {
+				if series.checked {
+					history[series.name] = m.windowPoints(series.name)
+					names = append(names, series.name)
+				}
+			}
+			return m, exportCSVCmd(history, names, m.metricName, m.exportPath)
+		case "[", "]":
+			// Step through the captured scrapes one at a time
+			if msg.String() == "[" {
+				m.stepFrame(-1)
+			} else {
+				m.stepFrame(1)
+			}
+			m.redrawChart()
+			m.rebuildLegend()
+		case "<", ">":
+			// Scrape twice as often, or half as often
+			factor := 0.5
+			if msg.String() == ">" {
+				factor = 2
+			}
+			return m, m.scaleInterval(factor)
+		case "P":
+			// Start over with the next profile of the config file
+			return m, m.switchProfile()
+		case "p":
+			// Freeze the chart, or resume with an immediate scrape
+			if !m.paused {
+				m.paused = true
+				return m, nil
+			}
+			return m, m.resume()
+		case "+", "-":
+			// Zoom the time axis, keeping the end of the viewed range in place
+			factor := 0.5
+			if msg.String() == "-" {
+				factor = 2
+			}
+			m.zoomTime(factor)
+			m.redrawChart()
+			return m, nil
+		case "f":
+			// Fetch a single scrape while paused, keeping the zoom and the stepped back
+			// frame, or go back to the live view of the full time range
+			if m.paused {
+				return m, m.fetchOnce()
+			}
+			m.followLive()
+			m.redrawChart()
+			m.rebuildLegend()
+		case "L":
+			// Lock or unlock the current set of visible series
+			m.seriesLocked = !m.seriesLocked
+		case "F":
+			// Show the full name of the hovered or only visible series, which the legend may truncate
+			if name, ok := m.targetSeries(); ok {
+				m.status = name
+			} else {
+				m.status = "Hover a legend entry or pick the series with s to show its full name"
+			}
+		case "C":
+			// Copy what the chart shows to share it
+			m.copyShareText()
+		case "M":
+			// Mark the current time with a labeled annotation, e.g. a deploy
+			m.annotating = true
+			m.annotationLabel = ""
+			m.annotationTime = time.Now()
+		case "U":
+			// Clear all annotations
+			m.annotations = nil
+			// Drawing skips the canvas without any data, which would keep the markers
+			m.redrawChart()
+			m.status = "Cleared annotations"
+		case "y":
+			// Copy the current value of the hovered or only visible series
+			if name, ok := m.targetSeries(); ok {
+				m.copyValue(name)
+			} else {
+				m.status = "Hover a legend entry or pick the series with s to copy its value"
+			}
+		case "h":
+			// Show the value histogram of the hovered or only visible series
+			if name, ok := m.targetSeries(); ok {
+				m.histogramSeries = name
+			} else {
+				m.status = "Hover a legend entry or pick the series with s to show its histogram"
+			}
+		case "B":
+			// Show the bucket counts of a histogram metric
+			if m.hasBuckets() {
+				m.showBuckets = true
+			} else {
+				m.status = "The metric has no histogram buckets (series ending in _bucket with an le label)"
 			}
+		case "x":
+			// Show the correlation of the marked series
+			m.showCorrelation = true
+		case "D":
+			// Show connection details of the last scrape
+			m.showDiagnostics = true
+		case "i":
+			// Toggle the TYPE and HELP info line
+			m.showInfo = !m.showInfo
+			m.resizeChart()
+		case "Y":
+			// Re-fit the Y axis to the current data, or on the next scrape if there is none yet
+			m.yRangeSet = false
+			m.redrawChart()
 		case "r":
-			// Reset the chart
+			// Reset the chart, hiding everything captured so far
+			m.windowStart = time.Now()
+			m.frameCursor = time.Time{}
 			m.chart.ClearAllData()
 			m.chart.Clear()
 			m.chart.DrawXYAxisAndLabel()
 			return m, nil
 		}
 
+	case tea.MouseMsg:
+		// Dragging the brush of the overview moves the viewed range of the chart
+		if m.showOverview && msg.Button == tea.MouseButtonLeft &&
+			(msg.Action == tea.MouseActionPress || msg.Action == tea.MouseActionMotion) &&
+			zone.Get("overview-brush").InBounds(msg) {
+			m.moveBrush(msg)
+			return m, nil
+		}
+		// Hovering the chart shows the nearest data point in the help bar
+		if msg.Action == tea.MouseActionMotion {
+			m.hover = nil
+			if x, y := zone.Get("chart").Pos(msg); x >= 0 && !m.showTable && m.infoSamples == nil {
+				if point, ok := m.hoverAt(x, y); ok {
+					m.hover = &point
+				}
+			}
+		}
+
 	case tea.WindowSizeMsg:
 		m.termWidth = msg.Width
 		m.termHeight = msg.Height
@@ -772,11 +2075,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 								color = m.seriesColors[series.colorIdx%len(m.seriesColors)]
 							}
 
-							style := lipgloss.NewStyle().Foreground(color)
-							m.chart.SetDataSetStyle(series.name, style)
+							m.styleSeries(series.name, color)
 						}
 
-						m.chart.DrawAll()
+						m.drawChart()
 
 						return m, nil
 					}
@@ -795,15 +2097,77 @@ func (m Model) View() string {
 	var sb strings.Builder
 
 	// ASCII art logo
-	logo := lipgloss.NewStyle().Foreground(lipgloss.Color("#ff5f00")).Render(
+	logo := lipgloss.NewStyle().Foreground(accentColor).Render(
 		"     __            __      _          \n" +
 			"    / / __ _  ___ / /_____(_)______   \n" +
 			"   / / /  ' \\/ -_) __/ __/ / __(_-<   \n" +
 			"  /_/ /_/_/_/\\__/\\__/_/ /_/\\__/___/   \n")
 
 	// Title section with logo and metric info
-	titleText := titleStyle.Render(fmt.Sprintf("   Metric: %s", m.metricName))
-	subtitleText := helpStyle.Render(fmt.Sprintf("   URL: %s | Interval: %s", m.url, m.interval))
+	metricTitle := m.metricName
+	if m.fetch.query != "" {
+		metricTitle = m.fetch.query
+	}
+	var metricTypes []string
+	for _, base := range m.metricBases() {
+		if metricType := m.seriesMeta(base).Type; metricType != "" && !slices.Contains(metricTypes, metricType) {
+			metricTypes = append(metricTypes, metricType)
+		}
+	}
+	if len(metricTypes) > 0 {
+		metricTitle += " [" + strings.Join(metricTypes, ", ") + "]"
+	}
+	if m.showAggregated {
+		metricTitle += " (" + m.aggregateOp
+		if len(m.groupBy) > 0 {
+			metricTitle += " by " + strings.Join(m.groupBy, ",")
+		}
+		metricTitle += ")"
+	}
+	if m.rateActive() {
+		metricTitle += " (rate/s)"
+	}
+	if m.cumulative {
+		metricTitle += " (cumulative)"
+	}
+	if m.logScale {
+		metricTitle += " (log)"
+	}
+	if m.referenceSeries != "" && !m.showAggregated {
+		metricTitle += " (% of " + labelPairs(m.referenceSeries) + ")"
+	}
+	if m.seriesLocked {
+		metricTitle += " (series locked)"
+	}
+	if m.stale() {
+		metricTitle += " (stale)"
+	}
+	if !m.frameCursor.IsZero() {
+		metricTitle += " (frame " + formatPointTime(m.frameCursor) + ")"
+	} else if !m.following() {
+		metricTitle += " (zoomed)"
+	}
+	if len(m.seriesList) > 0 {
+		metricTitle += " | " + m.seriesCountText()
+	}
+	titleText := titleStyle.Render(fmt.Sprintf("   Metric: %s", metricTitle)) + " " + m.fetchIndicator()
+	var urls []string
+	for _, u := range m.urls {
+		urls = append(urls, redactURL(u))
+	}
+	subtitle := fmt.Sprintf("   URL: %s | Interval: %s", strings.Join(urls, ", "), m.interval)
+	if m.profile != "" {
+		subtitle += " | Profile: " + m.profile
+	}
+	subtitleText := helpStyle.Render(subtitle)
+	// Re-rendered on every tick, so the age keeps counting while no scrapes succeed
+	if updated, overdue := m.updatedText(time.Now()); updated != "" {
+		updatedStyle := helpStyle
+		if overdue {
+			updatedStyle = updatedStyle.Foreground(staleColor)
+		}
+		subtitleText += helpStyle.Render(" | ") + updatedStyle.Render(updated)
+	}
 
 	header := lipgloss.JoinHorizontal(
 		lipgloss.Top,
@@ -815,15 +2179,62 @@ func (m Model) View() string {
 				subtitleText,
 			)),
 	)
+	// Without the logo its name leads the title, with the subtitle lined up below
+	if m.hideLogo {
+		name := lipgloss.NewStyle().Foreground(accentColor).Bold(true).Render("  /metrics")
+		header = lipgloss.JoinVertical(lipgloss.Left, name+titleText, strings.Repeat(" ", lipgloss.Width(name))+subtitleText)
+	}
+
+	if !m.fullscreen {
+		sb.WriteString(header)
+		sb.WriteString("\n")
+	}
+
+	// Show the keybinding overlay if active
+	if m.showHelp {
+		sb.WriteString(m.helpOverlayView())
+		return zone.Scan(defaultStyle.Render(sb.String()))
+	}
 
-	sb.WriteString(header)
-	sb.WriteString("\n")
+	// Show the histogram overlay if active
+	if m.histogramSeries != "" {
+		sb.WriteString(m.histogramView())
+		return zone.Scan(defaultStyle.Render(sb.String()))
+	}
+
+	// Show the bucket distribution if active
+	if m.showBuckets {
+		sb.WriteString(m.bucketsView())
+		return zone.Scan(defaultStyle.Render(sb.String()))
+	}
+
+	// Show the correlation overlay if active
+	if m.showCorrelation {
+		sb.WriteString(m.correlationView())
+		return zone.Scan(defaultStyle.Render(sb.String()))
+	}
+
+	// Show the global search if active
+	if m.searching {
+		sb.WriteString(m.searchView())
+		return zone.Scan(defaultStyle.Render(sb.String()))
+	}
+
+	// Show the diagnostics overlay if active
+	if m.showDiagnostics {
+		sb.WriteString(m.diagnosticsView())
+		return zone.Scan(defaultStyle.Render(sb.String()))
+	}
 
 	// Show select mode if active
 	if m.selectMode {
 		sb.WriteString(m.metricsList.View())
 		sb.WriteString("\n")
-		sb.WriteString(helpStyle.Render("Press Enter to select, Esc/q to cancel, / to filter"))
+		if m.hiddenMetrics > 0 {
+			sb.WriteString(labelStyle.Render(fmt.Sprintf("+%d more metrics not listed, narrow them down with --filter", m.hiddenMetrics)))
+			sb.WriteString("\n")
+		}
+		sb.WriteString(m.modeHelpContent(modeMetricSelect))
 		return sb.String()
 	}
 
@@ -838,24 +2249,42 @@ func (m Model) View() string {
 			maxVisible = 3
 		}
 
+		indices := m.filteredSeries()
+		mixed := m.mixedMetrics()
+		if m.seriesFiltering || m.seriesFilter != "" {
+			filterLine := fmt.Sprintf("Filter: %s", m.seriesFilter)
+			if m.seriesFiltering {
+				filterLine += "█"
+			}
+			sb.WriteString(labelStyle.Render(fmt.Sprintf("%s (%d of %d)", filterLine, len(indices), len(m.seriesList))))
+			sb.WriteString("\n\n")
+		}
+
 		start := m.seriesListScroll
 		end := start + maxVisible
-		if end > len(m.seriesList) {
-			end = len(m.seriesList)
+		if end > len(indices) {
+			end = len(indices)
 		}
 
 		// Render visible items
-		for i := start; i < end; i++ {
+		for pos := start; pos < end; pos++ {
+			i := indices[pos]
 			sel := " "
-			if i == m.seriesListSelected {
+			if pos == m.seriesListSelected {
 				sel = ">"
 			}
 			check := " "
 			if m.seriesList[i].checked {
 				check = "✓"
 			}
-			line := fmt.Sprintf("%s [%s] %s", sel, check, m.seriesList[i].name)
-			if i == m.seriesListSelected {
+			line := fmt.Sprintf("%s [%s] %s", sel, check, metricLabel(m.seriesList[i].name, mixed))
+			if slices.Contains(m.correlated, m.seriesList[i].name) {
+				line += " ⇄"
+			}
+			if m.referenceSeries == m.seriesList[i].name {
+				line += " (100%)"
+			}
+			if pos == m.seriesListSelected {
 				sb.WriteString(listSelectedItemStyle.Render(line))
 			} else {
 				sb.WriteString(listItemStyle.Render(line))
@@ -864,18 +2293,45 @@ func (m Model) View() string {
 		}
 
 		sb.WriteString("\n")
-		sb.WriteString(helpStyle.Render("Space: Toggle | Enter: Accept | a: Toggle All | Esc/q: Cancel | ↑↓: Navigate"))
+		sb.WriteString(m.modeHelpContent(modeSeriesSelect))
+		if m.status != "" {
+			sb.WriteString("\n")
+			sb.WriteString(labelStyle.Render(m.status))
+		}
 		return sb.String()
 	}
 
-	// Error display
-	if m.err != nil {
+	// Error display, unless the placeholder of the empty chart shows it
+	if m.err != nil && !m.waitingForData() {
 		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(fmt.Sprintf("⚠️  Error: %v", m.err)))
+		if m.emptyScrapes > 0 {
+			sb.WriteString(helpStyle.Render(" (" + m.retryText(time.Now()) + ")"))
+		}
 		sb.WriteString("\n\n")
 	}
 
+	// Metric info
+	if m.showInfo {
+		sb.WriteString(m.infoView())
+		sb.WriteString("\n")
+	}
+
 	// Chart and Legend
-	chartView := borderStyle.Render(m.chart.View())
+	chartView := zone.Mark("chart", m.chart.View())
+	if m.waitingForData() {
+		chartView = m.placeholderView()
+	}
+	if m.infoSamples != nil {
+		chartView = m.infoPanelView()
+	} else if m.showTable {
+		chartView = m.tableView()
+	}
+	if !m.hideBorder {
+		chartView = borderStyle.Render(chartView)
+	}
+	if m.showOverview {
+		chartView = lipgloss.JoinVertical(lipgloss.Left, chartView, m.overviewView())
+	}
 
 	if m.showLegend && len(m.seriesList) > 0 {
 		m.updateLegendViewportSize()
@@ -890,41 +2346,50 @@ func (m Model) View() string {
 
 		legend = lipgloss.NewStyle().
 			BorderStyle(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("#ff5f00")).
+			BorderForeground(accentColor).
 			Padding(1).
-			Width(legendBoxWidth).
-			Height(m.height).
+			Width(m.legendWidth).
+			Height(m.legendHeight()).
 			Render(legend)
 
 		// Join chart and legend horizontally
-		chartAndLegend := lipgloss.JoinHorizontal(lipgloss.Top, chartView, " ", legend)
-		chartWithMargin := lipgloss.NewStyle().MarginLeft(2).MarginRight(2).Render(chartAndLegend)
-		sb.WriteString(chartWithMargin)
-	} else {
-		chartWithMargin := lipgloss.NewStyle().MarginLeft(2).MarginRight(2).Render(chartView)
-		sb.WriteString(chartWithMargin)
+		chartView = lipgloss.JoinHorizontal(lipgloss.Top, chartView, " ", legend)
+	}
+
+	chartWithMargin := lipgloss.NewStyle().MarginLeft(2).MarginRight(2).Render(chartView)
+	if m.capWidth {
+		chartWithMargin = lipgloss.PlaceHorizontal(m.termWidth, lipgloss.Center, chartView)
+	}
+	sb.WriteString(chartWithMargin)
+	if m.fullscreen {
+		return zone.Scan(defaultStyle.Render(sb.String()))
 	}
 
 	// Calculate remaining vertical space to push help bar to bottom
-	// Count lines: logo (4) + 1 newlines after header + chart (m.height) + chart borders (~2)
+	// Count lines: header (see headerHeight) + chart (m.height) + chart borders (~2)
 	// The title section adds to logo lines (JoinHorizontal keeps max height)
-	usedLines := 4 + 1 + m.height + 2 + 0          // +1 for help bar
+	usedLines := m.headerHeight() + m.height + m.chartBorderSize() // +1 for help bar
+	if m.showInfo {
+		usedLines += lipgloss.Height(m.infoView())
+	}
+	if m.showOverview {
+		usedLines += overviewHeight
+	}
 	remainingLines := m.termHeight - usedLines - 0 // -3 to account for the extra lines
 	if remainingLines > 0 {
 		sb.WriteString(strings.Repeat("\n", remainingLines))
 	}
 
-	// Help
-	keyStyle := lipgloss.NewStyle().Background(lipgloss.Color("237")).Foreground(lipgloss.Color("15")).Bold(true)
-	valStyle := lipgloss.NewStyle().Background(lipgloss.Color("15")).Foreground(lipgloss.Color("0"))
-
-	helpContent := keyStyle.Render("q") + valStyle.Render("Quit") + "  " +
-		keyStyle.Render("m") + valStyle.Render("Metrics") + "  " +
-		keyStyle.Render("s") + valStyle.Render("Series") + "  " +
-		keyStyle.Render("l") + valStyle.Render("Legend") + "  " +
-		keyStyle.Render("r") + valStyle.Render("Reset")
-	if m.showLegend && m.legendViewport.TotalLineCount() > m.legendViewport.VisibleLineCount() {
-		helpContent += "  " + keyStyle.Render("↑↓") + valStyle.Render("Scroll")
+	// Help, or the label of the annotation being typed
+	helpContent := m.helpBarContent()
+	if m.annotating {
+		helpContent = fmt.Sprintf("Annotation: %s█  Enter to add, Esc to cancel", m.annotationLabel)
+	}
+	if m.status != "" {
+		helpContent += "  " + m.status
+	}
+	if m.hover != nil {
+		helpContent += "  " + m.hoverText()
 	}
 
 	helpBar := lipgloss.NewStyle().
@@ -937,38 +2402,290 @@ func (m Model) View() string {
 	return zone.Scan(defaultStyle.Render(sb.String()))
 }
 
-func runApp(url string) error {
-	selectedMetric := metricFlag
-	if selectedMetric == "" {
-		metrics, err := fetchAllMetrics(url)
+// newFetchConfig builds the request configuration from the command line flags
+func newFetchConfig() (fetchConfig, error) {
+	cfg := fetchConfig{
+		method:    strings.ToUpper(methodFlag),
+		userAgent: userAgentFlag,
+		query:     queryFlag,
+		client:    &http.Client{Timeout: timeoutFlag},
+	}
+	// Requests go through the proxy of HTTP_PROXY, HTTPS_PROXY and NO_PROXY unless --proxy overrides it
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if insecureFlag {
+		transport = insecureTransport()
+	}
+	if proxyFlag != "" {
+		proxy, err := parseProxyURL(proxyFlag)
+		if err != nil {
+			return cfg, err
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	cfg.client.Transport = transport
+	if userFlag != "" {
+		username, password, err := parseUser(userFlag)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.username, cfg.password = username, password
+	}
+	cfg.token = tokenFlag
+	if tokenFileFlag != "" {
+		token, err := readToken(tokenFileFlag)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.token = token
+	}
+	if len(headerFlag) > 0 {
+		header, err := parseHeaders(headerFlag)
 		if err != nil {
-			return fmt.Errorf("error fetching metrics: %w", err)
+			return cfg, err
 		}
-		if len(metrics) == 0 {
-			return fmt.Errorf("no metrics found at the endpoint")
+		cfg.header = header
+	}
+	if execCredFlag != "" {
+		cfg.credential = &execCredential{command: execCredFlag, ttl: execCredTTLFlag}
+	}
+	if bodyFlag != "" {
+		body, err := readRequestBody(bodyFlag)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.body = body
+		cfg.contentType = bodyContentType(body)
+	}
+	return cfg, nil
+}
+
+// runApp runs the UI, starting it over whenever another profile is picked
+func runApp(flags *pflag.FlagSet, urls []string) error {
+	profiles, err := loadProfiles(configFlag)
+	if err != nil {
+		return err
+	}
+	explicit := explicitFlags(flags)
+
+	name := profileFlag
+	for {
+		targets := urls
+		if name != "" {
+			p, ok := profiles[name]
+			if !ok {
+				return fmt.Errorf("unknown profile %q (config file %s)", name, configFlag)
+			}
+			if err := p.apply(flags, explicit); err != nil {
+				return fmt.Errorf("profile %q: %w", name, err)
+			}
+			if len(targets) == 0 && p.URL != "" {
+				targets = []string{p.URL}
+			}
+		}
+		if len(targets) == 0 {
+			return fmt.Errorf("a URL is required unless the profile sets one")
+		}
+
+		next, err := runProfile(flags, targets, name, profileNames(profiles))
+		if err != nil || next == "" {
+			return err
+		}
+
+		// The URLs on the command line only apply to the first profile
+		resetFlags(flags, explicit)
+		name, urls = next, nil
+	}
+}
+
+// runProfile runs the UI against the URLs until it quits and returns the profile
+// to switch to, if any. Metrics are listed from the first URL.
+func runProfile(flags *pflag.FlagSet, urls []string, profile string, profiles []string) (string, error) {
+	url := urls[0]
+	cfg, err := newFetchConfig()
+	if err != nil {
+		return "", err
+	}
+
+	match := metricMatch(metricMatchFlag)
+	if _, err := match.matcher(metricFlag); err != nil {
+		return "", err
+	}
+	dups := dupPolicy(dupPolicyFlag)
+	if err := dups.validate(); err != nil {
+		return "", err
+	}
+	op := thresholdOp(thresholdOpFlag)
+	if err := op.validate(); err != nil {
+		return "", err
+	}
+	unit := valueUnit(unitFlag)
+	if err := unit.validate(); err != nil {
+		return "", err
+	}
+	t, ok := themes[themeFlag]
+	if !ok {
+		return "", fmt.Errorf("invalid --theme value %q (expected %s)", themeFlag, strings.Join(themeNames(), ", "))
+	}
+
+	if listFlag {
+		return "", listMetrics(cfg, url, os.Stdout)
+	}
+
+	selectedMetric := metricFlag
+	// Query results are named after the query rather than a scraped metric
+	if cfg.query != "" {
+		selectedMetric, match = queryMetric, matchExact
+	}
+	if selectedMetric == "" && !noStateFlag {
+		selectedMetric = rememberedMetric(cfg, url, defaultStatePath())
+	}
+	if selectedMetric == "" {
+		switch autoSelectFlag {
+		case "first":
+			metrics, err := fetchAllMetrics(cfg, url)
+			if err != nil {
+				return "", fmt.Errorf("error fetching metrics: %w", err)
+			}
+			if len(metrics) == 0 {
+				return "", fmt.Errorf("no metrics found at the endpoint")
+			}
+			selectedMetric = metrics[0]
+		case "active":
+			values, err := fetchAllMetricValues(cfg, url)
+			if err != nil {
+				return "", fmt.Errorf("error fetching metrics: %w", err)
+			}
+			if len(values) == 0 {
+				return "", fmt.Errorf("no metrics found at the endpoint")
+			}
+			selectedMetric = mostActiveMetric(values)
+		default:
+			return "", fmt.Errorf("invalid --auto-select value %q (expected first or active)", autoSelectFlag)
 		}
-		selectedMetric = metrics[0]
 	}
 
+	if onceFlag {
+		return "", runOnce(cfg, urls, selectedMetric, match, dups, byFlag, os.Stdout)
+	}
+
+	applyTheme(t)
+	if noColorRequested(noColorFlag) {
+		disableColors()
+	}
 	zone.NewGlobal()
 
 	m := NewModel(url, selectedMetric, intervalFlag)
+	m.fetch = cfg
+	m.urls = urls
+	m.profile = profile
+	m.profiles = profiles
+	m.metricMatch = match
+	m.hideBorder = noBorderFlag
+	m.hideLogo = noLogoFlag
+	m.legendMax = legendMaxFlag
+	if legendWidthFlag < minLegendWidth {
+		return "", fmt.Errorf("--legend-width must be at least %d", minLegendWidth)
+	}
+	m.legendWidth = legendWidthFlag
+	m.seriesBy = byFlag
+	m.metricFilter = filterFlag
+	m.maxMetrics = maxMetricsFlag
+	m.includeInfo = includeInfoFlag
+	m.histogramBuckets = bucketsFlag
+	m.trendWindow = trendWindowFlag
+	m.showStats = legendStatsFlag
+	// Sparklines get the room left besides the trend arrows and the narrowest label
+	maxSpark := legendLabelMax(m.legendWidth, 0, m.trendWindow > 0) - minLegendLabel - 1
+	if sparklineFlag < 0 || sparklineFlag > maxSpark {
+		return "", fmt.Errorf("--sparkline must be between 0 and %d to fit the legend", maxSpark)
+	}
+	m.sparkPoints = sparklineFlag
+	m.maxPoints = maxPointsFlag
+	m.staleScrapes = staleFlag
+	m.maxSeries = maxSeriesFlag
+	m.dupPolicy = dups
+	m.exportPath = exportPathFlag
+	m.rate = rateFlag
+	m.logScale = logScaleFlag
+	m.unit = unit
+	if precisionFlag < adaptivePrecision {
+		return "", fmt.Errorf("--precision must be at least %d", adaptivePrecision)
+	}
+	m.precision = precisionFlag
+	m.applyYScale()
+	m.xRelative = xRelativeFlag
+	m.applyXScale()
+	m.remoteWriteURL = remoteWriteFlag
+	m.paused = manualFlag
+	if flags.Changed("y-min") {
+		m.yMin = &yMinFlag
+	}
+	if flags.Changed("y-max") {
+		m.yMax = &yMaxFlag
+	}
+	if m.yMin != nil && m.yMax != nil && yMinFlag >= yMaxFlag {
+		return "", fmt.Errorf("--y-min must be less than --y-max")
+	}
+	if flags.Changed("threshold") {
+		m.threshold = &thresholdFlag
+	}
+	m.thresholdOp = op
+	if seriesFlag != "" {
+		seriesMatch, err := regexp.Compile(seriesFlag)
+		if err != nil {
+			return "", fmt.Errorf("invalid --series-filter value: %w", err)
+		}
+		m.seriesMatch = seriesMatch
+	}
+	if highlightFlag != "" {
+		highlight, err := regexp.Compile(highlightFlag)
+		if err != nil {
+			return "", fmt.Errorf("invalid --highlight value: %w", err)
+		}
+		m.highlight = highlight
+	}
+	if aggregateFlag != "" || len(groupByFlag) > 0 {
+		if aggregateFlag != "" {
+			if _, ok := aggregateFuncs[aggregateFlag]; !ok {
+				return "", fmt.Errorf("invalid --aggregate value %q (expected sum, avg, min or max)", aggregateFlag)
+			}
+			m.aggregateOp = aggregateFlag
+		}
+		m.groupBy = groupByFlag
+		m.showAggregated = true
+	}
+	// Anything printed now would be hidden by the alt screen, so warn in the status line
+	if insecureFlag {
+		m.status = "Warning: TLS certificate verification is disabled by --insecure"
+	}
 	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseAllMotion())
 
-	if len(os.Getenv("DEBUG")) > 0 {
+	if debugLogging() {
 		f, err := tea.LogToFile("debug.log", "debug")
 		if err != nil {
 			fmt.Println("fatal:", err)
 			os.Exit(1)
 		}
 		defer f.Close()
+
+		if insecureFlag {
+			log.Println("warning: TLS certificate verification is disabled by --insecure")
+		}
 	}
 
-	if _, err := p.Run(); err != nil {
-		return err
+	final, err := p.Run()
+	if err != nil {
+		return "", err
 	}
 
-	return nil
+	// Patterns given with --metric-match don't name a single metric to start with
+	last := final.(Model)
+	if !noStateFlag && last.metricMatch == matchExact && last.fetch.query == "" {
+		if err := saveState(defaultStatePath(), appState{Metric: last.metricName}); err != nil {
+			log.Println("warning:", err)
+		}
+	}
+	return last.nextProfile, nil
 }
 
 func main() {
@@ -1,11 +1,26 @@
 package main
 
 import (
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/NimbleMarkets/ntcharts/linechart/timeserieslinechart"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
 )
 
 func TestYLabelFormatter(t *testing.T) {
-	formatter := yLabelFormatter()
+	formatter := yLabelFormatter(adaptivePrecision)
 	tests := []struct {
 		name string
 		val  float64
@@ -25,4 +40,514 @@ func TestYLabelFormatter(t *testing.T) {
 			t.Fatalf("%s: expected %s, got %s", tt.name, tt.want, got)
 		}
 	}
+
+	fixed := yLabelFormatter(3)
+	for _, tt := range []struct {
+		val  float64
+		want string
+	}{
+		{0, "0.000"},
+		{0.456, "0.456"},
+		{5120, "5120.000"},
+		{-123.45, "-123.450"},
+	} {
+		if got := fixed(0, tt.val); got != tt.want {
+			t.Fatalf("fixed precision of %v: expected %s, got %s", tt.val, tt.want, got)
+		}
+	}
+	if got := yLabelFormatter(0)(0, 42.7); got != "43" {
+		t.Fatalf("expected no decimal places, got %s", got)
+	}
+}
+
+func TestFormatPointTime(t *testing.T) {
+	ts := time.Date(2024, 3, 5, 14, 7, 9, 123456789, time.FixedZone("CET", 3600))
+	if got, want := formatPointTime(ts), "2024-03-05 14:07:09.123 CET"; got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+}
+
+func TestSoloStep(t *testing.T) {
+	m := NewModel("http://localhost", "metric", time.Second)
+	m.seriesList = []seriesItem{
+		{name: "metric{a=\"1\"}", checked: true, colorIdx: 0},
+		{name: "metric{a=\"2\"}", checked: false, colorIdx: 1},
+		{name: "metric{a=\"3\"}", checked: true, colorIdx: 2},
+	}
+
+	visible := func() []bool {
+		out := make([]bool, len(m.seriesList))
+		for i, s := range m.seriesList {
+			out[i] = s.checked
+		}
+		return out
+	}
+
+	m.soloStep(1)
+	if got := visible(); !reflect.DeepEqual(got, []bool{true, false, false}) {
+		t.Fatalf("first step: unexpected visibility %v", got)
+	}
+	m.soloStep(1)
+	if got := visible(); !reflect.DeepEqual(got, []bool{false, false, true}) {
+		t.Fatalf("second step: unexpected visibility %v", got)
+	}
+	m.soloStep(1)
+	if got := visible(); !reflect.DeepEqual(got, []bool{true, false, false}) {
+		t.Fatalf("wrap around: unexpected visibility %v", got)
+	}
+	m.soloStep(-1)
+	if got := visible(); !reflect.DeepEqual(got, []bool{false, false, true}) {
+		t.Fatalf("step back: unexpected visibility %v", got)
+	}
+
+	m.exitSolo()
+	if got := visible(); !reflect.DeepEqual(got, []bool{true, false, true}) {
+		t.Fatalf("exit: expected original visibility, got %v", got)
+	}
+	if m.soloVisibility != nil {
+		t.Fatalf("expected solo state to be cleared")
+	}
+}
+
+func TestTopSeries(t *testing.T) {
+	zone.NewGlobal()
+	m := NewModel("http://localhost", "metric", time.Second)
+	model, _ := m.Update(MetricsMsg{Samples: []MetricSample{
+		{FullName: `metric{s="a"}`, Value: 1},
+		{FullName: `metric{s="b"}`, Value: 10},
+		{FullName: `metric{s="c"}`, Value: 5},
+		{FullName: `metric{s="d"}`, Value: 7},
+	}, Time: time.Now()})
+	m = model.(Model)
+
+	if got := m.topSeries([]int{0, 1, 2, 3}, 2); !reflect.DeepEqual(got, []int{1, 3}) {
+		t.Fatalf("expected [1 3], got %v", got)
+	}
+	if got := m.topSeries([]int{0, 2}, 5); !reflect.DeepEqual(got, []int{0, 2}) {
+		t.Fatalf("expected all indices when under the cap, got %v", got)
+	}
+}
+
+func TestLegendSortsByPlottedValue(t *testing.T) {
+	zone.NewGlobal()
+	m := NewModel("http://localhost", "requests_total", time.Second)
+	m.metadata = map[string]MetricMeta{"requests_total": {Type: "counter"}}
+	m.rate = true
+	start := time.Unix(1700000000, 0)
+	// The busiest counter has the lowest total, and one series is NaN
+	for i, values := range [][3]float64{{1000, 0, 0}, {1010, 100, math.NaN()}} {
+		model, _ := m.Update(MetricsMsg{Samples: []MetricSample{
+			{FullName: `requests_total{s="a"}`, Value: values[0]},
+			{FullName: `requests_total{s="b"}`, Value: values[1]},
+			{FullName: `requests_total{s="c"}`, Value: values[2]},
+		}, Time: start.Add(time.Duration(i) * time.Second)})
+		m = model.(Model)
+	}
+
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	m = model.(Model)
+	m.legendViewport.Width, m.legendViewport.Height = 40, 20
+	m.rebuildLegend()
+	legend := m.legendViewport.View()
+	b, a, c := strings.Index(legend, "s=b"), strings.Index(legend, "s=a"), strings.Index(legend, "s=c")
+	if b < 0 || a < 0 || c < 0 || b > a || a > c {
+		t.Fatalf("expected the legend ordered by rate with NaN last, got\n%s", legend)
+	}
+}
+
+func TestSortLinesByValue(t *testing.T) {
+	now := time.Now()
+	point := func(v float64) []timeserieslinechart.TimePoint {
+		return []timeserieslinechart.TimePoint{{Time: now, Value: v}}
+	}
+	lines := []plotLine{
+		{name: "a", colorIdx: 0, points: point(1)},
+		{name: "empty", colorIdx: 1},
+		{name: "b", colorIdx: 2, points: point(10)},
+		{name: "c", colorIdx: 3, points: point(5)},
+		{name: "nan", colorIdx: 4, points: point(math.NaN())},
+	}
+
+	sortLinesByValue(lines)
+
+	var names []string
+	for _, line := range lines {
+		names = append(names, line.name)
+	}
+	if want := []string{"b", "c", "a", "empty", "nan"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("expected %v, got %v", want, names)
+	}
+	if lines[0].colorIdx != 2 {
+		t.Fatalf("expected color index to move with the line, got %d", lines[0].colorIdx)
+	}
+}
+
+func TestSeriesStyle(t *testing.T) {
+	m := NewModel("http://localhost", "metric", time.Second)
+	if style := m.seriesStyle(`up{instance="prod"}`, "46"); style.GetBold() || style.GetFaint() {
+		t.Fatal("expected a plain style without --highlight")
+	}
+
+	m.highlight = regexp.MustCompile(`instance="prod"`)
+	if style := m.seriesStyle(`up{instance="prod"}`, "46"); !style.GetBold() {
+		t.Fatal("expected matching series to be bold")
+	}
+	if style := m.seriesStyle(`up{instance="dev"}`, "46"); !style.GetFaint() {
+		t.Fatal("expected other series to be dimmed")
+	}
+}
+
+func TestFullSeriesName(t *testing.T) {
+	zone.NewGlobal()
+	m := NewModel("http://localhost", "http_requests_total", time.Second)
+	name := `http_requests_total{handler="/api/v1/very/long/path/that/gets/truncated",method="GET"}`
+	model, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	model, _ = model.Update(MetricsMsg{
+		Samples: []MetricSample{{FullName: name, Value: 1}},
+		Time:    time.Unix(1000, 0),
+	})
+	m = model.(Model)
+	if strings.Contains(m.legendViewport.View(), "truncated") {
+		t.Fatalf("expected the legend to truncate the series name, got\n%s", m.legendViewport.View())
+	}
+
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("F")})
+	if got := model.(Model).status; got != name {
+		t.Fatalf("expected the full series name in the status, got %q", got)
+	}
+}
+
+func TestLegendWidthMinimum(t *testing.T) {
+	defer func(w int, metric string, noState bool) {
+		legendWidthFlag, metricFlag, noStateFlag = w, metric, noState
+	}(legendWidthFlag, metricFlag, noStateFlag)
+	t.Setenv("NO_COLOR", "")
+	legendWidthFlag, metricFlag, noStateFlag = minLegendWidth-1, "up", true
+
+	_, err := runProfile(rootCmd.Flags(), []string{"http://localhost"}, "", nil)
+	if err == nil || err.Error() != "--legend-width must be at least 20" {
+		t.Fatalf("expected the legend width to be rejected, got %v", err)
+	}
+}
+
+func TestFullscreenChart(t *testing.T) {
+	zone.NewGlobal()
+	m := NewModel("http://localhost", "up", time.Second)
+	model, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = model.(Model)
+	height := m.height
+
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("z")})
+	m = model.(Model)
+	if m.height != height+7 {
+		t.Fatalf("expected the chart to take the header and help bar lines, got height %d from %d", m.height, height)
+	}
+	view := m.View()
+	if strings.Contains(view, "Metric: up") || lipgloss.Height(view) > 40 {
+		t.Fatalf("expected only the chart within the terminal, got %d lines:\n%s", lipgloss.Height(view), view)
+	}
+
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("z")})
+	if model.(Model).height != height {
+		t.Fatal("expected the normal layout to be restored")
+	}
+}
+
+func TestNoLogoHeader(t *testing.T) {
+	zone.NewGlobal()
+	for _, hideLogo := range []bool{false, true} {
+		m := NewModel("http://localhost", "up", time.Second)
+		m.hideLogo = hideLogo
+		model, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+		m = model.(Model)
+
+		view := m.View()
+		if got := lipgloss.Height(view); got != 40 {
+			t.Fatalf("hideLogo=%v: expected the view to fill the terminal, got %d lines", hideLogo, got)
+		}
+		if hideLogo && !strings.HasPrefix(strings.TrimSpace(view), "/metrics   Metric: up") {
+			t.Fatalf("expected a single-line title, got:\n%s", view)
+		}
+	}
+}
+
+func TestLockedSeriesAreAddedHidden(t *testing.T) {
+	zone.NewGlobal()
+	m := NewModel("http://localhost", "up", time.Second)
+	now := time.Now()
+
+	lock := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("L")}
+
+	model, _ := m.Update(MetricsMsg{Samples: []MetricSample{{FullName: `up{instance="a"}`, Value: 1}}, Time: now})
+	model, _ = model.Update(lock)
+	m = model.(Model)
+	if !m.seriesLocked || !strings.Contains(m.helpBarContent(), "Lock/unlock series set") {
+		t.Fatal("expected L to lock the series set and show it in the help bar")
+	}
+
+	model, _ = m.Update(MetricsMsg{Samples: []MetricSample{
+		{FullName: `up{instance="a"}`, Value: 1},
+		{FullName: `up{instance="b"}`, Value: 1},
+	}, Time: now.Add(time.Second)})
+	m = model.(Model)
+
+	if len(m.seriesList) != 2 {
+		t.Fatalf("expected the new series to be listed, got %d series", len(m.seriesList))
+	}
+	if !m.seriesList[0].checked || m.seriesList[1].checked {
+		t.Fatalf("expected only the existing series to be visible, got %+v", m.seriesList)
+	}
+
+	// Unlocked, new series are shown again
+	model, _ = m.Update(lock)
+	model, _ = model.Update(MetricsMsg{Samples: []MetricSample{
+		{FullName: `up{instance="a"}`, Value: 1},
+		{FullName: `up{instance="c"}`, Value: 1},
+	}, Time: now.Add(2 * time.Second)})
+	m = model.(Model)
+	if m.seriesLocked || len(m.seriesList) != 3 || !m.seriesList[2].checked {
+		t.Fatalf("expected L to unlock the series set, got %+v", m.seriesList)
+	}
+}
+
+func TestSeriesMatchHidesOtherSeries(t *testing.T) {
+	zone.NewGlobal()
+	m := NewModel("http://localhost", "http_requests_total", time.Second)
+	m.seriesMatch = regexp.MustCompile(`method="POST"`)
+
+	model, _ := m.Update(MetricsMsg{Samples: []MetricSample{
+		{FullName: `http_requests_total{method="GET"}`, Value: 1},
+		{FullName: `http_requests_total{method="POST"}`, Value: 1},
+	}, Time: time.Now()})
+	m = model.(Model)
+
+	if len(m.seriesList) != 2 || m.seriesList[0].checked || !m.seriesList[1].checked {
+		t.Fatalf("expected only the matching series to be shown, got %+v", m.seriesList)
+	}
+}
+
+func TestReappearingSeriesKeepVisibility(t *testing.T) {
+	zone.NewGlobal()
+	m := NewModel("http://localhost", "up", time.Second)
+	now := time.Now()
+	samples := []MetricSample{
+		{FullName: `up{instance="a"}`, Value: 1},
+		{FullName: `up{instance="b"}`, Value: 1},
+	}
+
+	model, _ := m.Update(MetricsMsg{Samples: samples, Time: now})
+	m = model.(Model)
+	m.setSeriesChecked(1, false)
+
+	// The series vanishes from a scrape and comes back with the next one
+	model, _ = m.Update(MetricsMsg{Samples: samples[:1], Time: now.Add(time.Second)})
+	model, _ = model.Update(MetricsMsg{Samples: samples, Time: now.Add(2 * time.Second)})
+	m = model.(Model)
+
+	if len(m.seriesList) != 2 || !m.seriesList[0].checked || m.seriesList[1].checked {
+		t.Fatalf("expected the reappearing series to stay hidden, got %v", m.seriesList)
+	}
+}
+
+func TestNonFiniteSamplesAreNotPlotted(t *testing.T) {
+	zone.NewGlobal()
+	m := NewModel("http://localhost", "rpc_duration_seconds", time.Second)
+	now := time.Now()
+
+	for i := range 2 {
+		model, _ := m.Update(MetricsMsg{Samples: []MetricSample{
+			{FullName: `rpc_duration_seconds{quantile="0.5"}`, Value: 2},
+			{FullName: `rpc_duration_seconds{quantile="0.99"}`, Value: math.NaN()},
+			{FullName: `rpc_duration_seconds{quantile="1"}`, Value: math.Inf(1)},
+		}, Time: now.Add(time.Duration(i) * time.Second)})
+		m = model.(Model)
+	}
+
+	if len(m.seriesList) != 3 {
+		t.Fatalf("expected all series to be listed, got %d series", len(m.seriesList))
+	}
+	if got := len(m.dataHistory[`rpc_duration_seconds{quantile="0.5"}`]); got != 2 {
+		t.Fatalf("expected the finite series to be recorded, got %d points", got)
+	}
+	if _, ok := m.dataHistory[`rpc_duration_seconds{quantile="0.99"}`]; ok {
+		t.Fatal("expected no points of the NaN series")
+	}
+	if minY, maxY := m.chart.ViewMinY(), m.chart.ViewMaxY(); !isFinite(minY) || !isFinite(maxY) || minY >= 2 || maxY <= 2 {
+		t.Fatalf("expected a finite range around the finite values, got %v to %v", minY, maxY)
+	}
+}
+
+func TestFailedScrapesMarkChartStale(t *testing.T) {
+	zone.NewGlobal()
+	m := NewModel("http://localhost", "up", time.Second)
+	m.staleScrapes = 2
+	now := time.Now()
+	scrapeErr := errors.New(`metric "up" not found`)
+
+	model, _ := m.Update(MetricsMsg{Samples: []MetricSample{{FullName: `up{instance="a"}`, Value: 1}}, Time: now})
+	m = model.(Model)
+
+	for range 2 {
+		model, _ = m.Update(MetricsMsg{Err: scrapeErr})
+		m = model.(Model)
+	}
+	if m.err != nil || !m.stale() {
+		t.Fatalf("expected a stale chart without error, got %v", m.err)
+	}
+
+	model, _ = m.Update(MetricsMsg{Err: scrapeErr})
+	m = model.(Model)
+	if m.err == nil || m.stale() {
+		t.Fatal("expected the error once the failures exceed the limit")
+	}
+
+	model, _ = m.Update(MetricsMsg{Samples: []MetricSample{{FullName: `up{instance="a"}`, Value: 1}}, Time: now.Add(time.Second)})
+	m = model.(Model)
+	if m.err != nil || m.stale() || m.emptyScrapes != 0 {
+		t.Fatalf("expected a successful scrape to recover, got %v", m.err)
+	}
+}
+
+func TestPauseToggle(t *testing.T) {
+	zone.NewGlobal()
+	m := NewModel("http://localhost", "up", time.Second)
+	p := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")}
+
+	model, cmd := m.Update(p)
+	m = model.(Model)
+	if !m.paused || cmd != nil {
+		t.Fatalf("expected to pause without a command, got paused=%v", m.paused)
+	}
+	if !strings.Contains(m.helpBarContent(), "PAUSED") {
+		t.Fatal("expected the help bar to show the pause")
+	}
+
+	model, cmd = m.Update(p)
+	m = model.(Model)
+	if m.paused || cmd == nil {
+		t.Fatalf("expected to resume with an immediate scrape, got paused=%v", m.paused)
+	}
+}
+
+func TestFetchOnceWhilePaused(t *testing.T) {
+	zone.NewGlobal()
+	m := NewModel("http://localhost", "up", time.Second)
+	if strings.Contains(m.helpBarContent(), "Fetch once") {
+		t.Fatal("expected no fetch binding while polling")
+	}
+	m.paused = true
+	if !strings.Contains(m.helpBarContent(), "Fetch once") {
+		t.Fatal("expected the fetch binding in the help bar while paused")
+	}
+
+	frame := time.Unix(1700000000, 0)
+	m.frameCursor = frame
+	model, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	m = model.(Model)
+	if !m.paused || cmd == nil {
+		t.Fatalf("expected a single scrape without resuming, got paused=%v", m.paused)
+	}
+	if !m.frameCursor.Equal(frame) {
+		t.Fatal("expected the stepped back frame to be kept")
+	}
+}
+
+func TestRefreshOnKeypress(t *testing.T) {
+	var scrapes atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		scrapes.Add(1)
+		_, _ = w.Write([]byte("up 1\n"))
+	}))
+	defer server.Close()
+
+	zone.NewGlobal()
+	m := NewModel(server.URL, "up", time.Millisecond)
+	// --refresh-on-keypress starts paused
+	m.paused = true
+	start := time.Now().Add(-time.Minute)
+	for i := range 20 {
+		model, _ := m.Update(MetricsMsg{
+			Samples: []MetricSample{{FullName: "up{}", Value: float64(i)}},
+			Time:    start.Add(time.Duration(i) * time.Second),
+		})
+		m = model.(Model)
+	}
+	for _, k := range []string{"+", "[", "["} {
+		model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		m = model.(Model)
+	}
+	frame, viewMin, viewMax := m.frameCursor, m.chart.ViewMinX(), m.chart.ViewMaxX()
+
+	// Ticks don't scrape while paused
+	_, cmd := m.Update(TickMsg{Gen: m.tickGen})
+	if _, ok := cmd().(TickMsg); !ok || scrapes.Load() != 0 {
+		t.Fatalf("expected only the next tick while paused, got %d scrapes", scrapes.Load())
+	}
+
+	model, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	m = model.(Model)
+	var fetched []tea.Msg
+	for _, c := range cmd().(tea.BatchMsg) {
+		if msg, ok := c().(MetricsMsg); ok {
+			fetched = append(fetched, msg)
+		}
+	}
+	if len(fetched) != 1 || scrapes.Load() != 1 {
+		t.Fatalf("expected exactly one fetch, got %d messages and %d scrapes", len(fetched), scrapes.Load())
+	}
+
+	model, _ = m.Update(fetched[0])
+	m = model.(Model)
+	if !m.paused || !m.frameCursor.Equal(frame) {
+		t.Fatalf("expected to stay paused on frame %v, got paused=%v at %v", frame, m.paused, m.frameCursor)
+	}
+	if m.chart.ViewMinX() != viewMin || m.chart.ViewMaxX() != viewMax {
+		t.Fatalf("expected the zoom to be kept, got %v-%v instead of %v-%v", m.chart.ViewMinX(), m.chart.ViewMaxX(), viewMin, viewMax)
+	}
+}
+
+func TestLegendViewportKeys(t *testing.T) {
+	keyMap := newLegendViewport(40).KeyMap
+	bindings := []key.Binding{keyMap.PageDown, keyMap.PageUp, keyMap.HalfPageDown, keyMap.HalfPageUp,
+		keyMap.Down, keyMap.Up, keyMap.Left, keyMap.Right}
+	for _, k := range []string{"j", "k", "u", "d"} {
+		if !key.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}, bindings...) {
+			t.Fatalf("expected %s to scroll the legend", k)
+		}
+	}
+	// These are chart keybindings
+	for _, k := range []string{"f", "b", "h", "l"} {
+		if key.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}, bindings...) {
+			t.Fatalf("expected %s not to scroll the legend", k)
+		}
+	}
+}
+
+func TestLegendGroupsQuantiles(t *testing.T) {
+	zone.NewGlobal()
+	m := NewModel("http://localhost", "rpc_duration", time.Second)
+	model, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	model, _ = model.Update(MetricsMsg{
+		Samples: []MetricSample{
+			{FullName: `rpc_duration{method="GET",quantile="0.99"}`, Value: 0.9},
+			{FullName: `rpc_duration{method="POST",quantile="0.5"}`, Value: 0.2},
+			{FullName: `rpc_duration{method="GET",quantile="0.5"}`, Value: 0.1},
+		},
+		Time: time.Unix(1000, 0),
+	})
+	m = model.(Model)
+
+	legend := m.legendViewport.View()
+	for _, want := range []string{"rpc_duration method=GET", "p50", "p99", "rpc_duration method=POST"} {
+		if !strings.Contains(legend, want) {
+			t.Fatalf("expected %q in the legend, got\n%s", want, legend)
+		}
+	}
+	if strings.Count(legend, "method=GET") != 1 {
+		t.Fatalf("expected a single header per summary, got\n%s", legend)
+	}
+	get := strings.Index(legend, "method=GET")
+	if p50, p99 := strings.Index(legend[get:], "p50"), strings.Index(legend[get:], "p99"); p50 > p99 {
+		t.Fatalf("expected the quantiles in ascending order, got\n%s", legend)
+	}
 }
@@ -2,31 +2,365 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"io"
+	"mime"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
+// defaultUserAgent identifies slashmetrics in the access logs of scraped targets
+func defaultUserAgent() string {
+	v, _, _ := buildInfo()
+	return "slashmetrics/" + v
+}
+
+// fetchConfig describes how requests against the metrics endpoint are made
+type fetchConfig struct {
+	method      string       // HTTP method, defaults to GET
+	body        []byte       // Request body sent with every scrape
+	contentType string       // Content type of the request body
+	userAgent   string       // User-Agent header, defaults to defaultUserAgent
+	username    string       // Basic auth user, credentials in the URL are used if empty
+	password    string       // Basic auth password
+	token       string       // Bearer token sent with every request
+	header      http.Header  // Extra headers, replacing the defaults above
+	client      *http.Client // Client used for requests, defaults to http.DefaultClient
+
+	credential *execCredential // Provides a bearer token for every request if set
+	query      string          // PromQL expression evaluated by the query API instead of scraping
+
+	diagnostics *connDiagnostics // Filled with connection details of the request if set
+}
+
+// do issues the configured request against the endpoint
+func (c fetchConfig) do(url string) (*http.Response, error) {
+	// Metrics files are read instead of requested
+	if path, ok := localPath(url); ok {
+		return openFile(path)
+	}
+
+	method := c.method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	var body io.Reader
+	defaults := http.Header{"Accept-Encoding": {"gzip"}}
+	if c.body != nil {
+		body = bytes.NewReader(c.body)
+		if c.contentType != "" {
+			defaults.Set("Content-Type", c.contentType)
+		}
+	}
+
+	req, err := c.newRequest(method, url, body, defaults)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	if c.diagnostics != nil {
+		req = c.diagnostics.trace(req)
+	}
+	resp, err := c.httpClient().Do(req)
+	if c.diagnostics != nil {
+		c.diagnostics.record(resp, time.Since(start))
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return nil, fmt.Errorf("request timed out after %s: %w", time.Since(start).Round(time.Millisecond), err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Asking for gzip ourselves turns off the transparent decompression of net/http
+	decoded, err := gunzip(resp.Body)
+	if err != nil {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to decompress response: %w", err)
+	}
+	resp.Body = decoded
+	return resp, nil
+}
+
+// newRequest builds a request with the configured User-Agent, headers and
+// credentials. The defaults are sent unless the extra headers replace them.
+func (c fetchConfig) newRequest(method, url string, body io.Reader, defaults http.Header) (*http.Request, error) {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	for key, values := range defaults {
+		req.Header[key] = values
+	}
+	req.Header.Set("User-Agent", c.agent())
+	for key, values := range c.header {
+		req.Header[key] = values
+	}
+
+	// Credentials in the URL userinfo are sent by net/http unless overridden here
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.credential != nil {
+		token, err := c.credential.Token()
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req, nil
+}
+
+// httpClient returns the configured client with its timeout, TLS and proxy settings
+func (c fetchConfig) httpClient() *http.Client {
+	if c.client == nil {
+		return http.DefaultClient
+	}
+	return c.client
+}
+
+// gzipBody closes both the gzip reader and the underlying body
+type gzipBody struct {
+	*gzip.Reader
+	body io.Closer
+}
+
+func (g gzipBody) Close() error {
+	g.Reader.Close()
+	return g.body.Close()
+}
+
+// gunzip decompresses a body that starts with the gzip magic bytes and passes
+// through any other body, e.g. one wrongly labeled as gzip
+func gunzip(body io.ReadCloser) (io.ReadCloser, error) {
+	br := bufio.NewReader(body)
+	magic, _ := br.Peek(2)
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return gzipBody{Reader: gz, body: body}, nil
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{br, body}, nil
+}
+
+// insecureTransport returns a transport that doesn't verify TLS certificates
+func insecureTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	return transport
+}
+
+// parseUser parses the user:password of --user. An empty password has to be
+// given explicitly, e.g. alice:, rather than being silently assumed.
+func parseUser(user string) (string, string, error) {
+	username, password, ok := strings.Cut(user, ":")
+	if !ok || username == "" {
+		return "", "", fmt.Errorf("invalid --user value %q (expected user:password)", username)
+	}
+	return username, password, nil
+}
+
+// parseProxyURL parses the URL of a --proxy, which HTTPS targets are tunneled through with CONNECT
+func parseProxyURL(proxy string) (*url.URL, error) {
+	u, err := url.Parse(proxy)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid --proxy URL %q (expected e.g. http://proxy:3128)", redactURL(proxy))
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+		return u, nil
+	default:
+		return nil, fmt.Errorf("unsupported --proxy scheme %q (expected http, https or socks5)", u.Scheme)
+	}
+}
+
+// agent returns the User-Agent header sent with every request
+func (c fetchConfig) agent() string {
+	if c.userAgent == "" {
+		return defaultUserAgent()
+	}
+	return c.userAgent
+}
+
+// parseHeaders parses "Key: Value" strings, accumulating repeated keys
+func parseHeaders(values []string) (http.Header, error) {
+	header := make(http.Header)
+	for _, value := range values {
+		key, val, ok := strings.Cut(value, ":")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid header %q (expected \"Key: Value\")", value)
+		}
+		header.Add(key, strings.TrimSpace(val))
+	}
+	return header, nil
+}
+
+// statusError describes a response that isn't 200 OK
+func statusError(code int) error {
+	switch code {
+	case http.StatusUnauthorized:
+		return fmt.Errorf("authentication failed (status code %d)", code)
+	case http.StatusForbidden:
+		return fmt.Errorf("forbidden (check token, status code %d)", code)
+	}
+	return fmt.Errorf("unexpected status code: %d", code)
+}
+
+// metricsContentTypes are the media types of the text expositions
+var metricsContentTypes = []string{"text/plain", "application/openmetrics-text"}
+
+// responseError checks that a scrape returned metrics. Login pages that an
+// endpoint redirects to would otherwise parse as an exposition without samples.
+func responseError(resp *http.Response) error {
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp.StatusCode)
+	}
+
+	// Exporters that don't set a content type are given the benefit of the doubt
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil && slices.Contains(metricsContentTypes, mediaType) {
+		return nil
+	}
+	if err == nil {
+		contentType = mediaType
+	}
+	if resp.Request != nil && resp.Request.Response != nil {
+		return fmt.Errorf("endpoint did not return Prometheus metrics (got %s after a redirect to %s)", contentType, redactURL(resp.Request.URL.String()))
+	}
+	return fmt.Errorf("endpoint did not return Prometheus metrics (got %s)", contentType)
+}
+
+// redactURL hides the password of credentials embedded in a URL
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	return u.Redacted()
+}
+
+// readRequestBody resolves the --body flag, reading the body from a file if it starts with @
+func readRequestBody(value string) ([]byte, error) {
+	if path, ok := strings.CutPrefix(value, "@"); ok {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		return data, nil
+	}
+	return []byte(value), nil
+}
+
+// readToken reads a bearer token from a file, ignoring surrounding whitespace
+// like the trailing newline of a mounted service account token
+func readToken(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// bodyContentType guesses the content type of a request body, treating
+// everything that isn't JSON as form data like curl does
+func bodyContentType(body []byte) string {
+	trimmed := bytes.TrimSpace(body)
+	if bytes.HasPrefix(trimmed, []byte("{")) || bytes.HasPrefix(trimmed, []byte("[")) {
+		return "application/json"
+	}
+	return "application/x-www-form-urlencoded"
+}
+
 // fetchAllMetrics fetches all available metric names from the endpoint
-func fetchAllMetrics(url string) ([]string, error) {
-	resp, err := http.Get(url)
+func fetchAllMetrics(cfg fetchConfig, url string) ([]string, error) {
+	resp, err := cfg.do(url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch metrics: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	if err := responseError(resp); err != nil {
+		return nil, err
+	}
+
+	return parseMetricNames(resp.Body)
+}
+
+// fetchMetricIndex fetches the names of all metrics along with their TYPE and HELP metadata
+func fetchMetricIndex(cfg fetchConfig, url string) ([]string, map[string]MetricMeta, error) {
+	resp, err := cfg.do(url)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch metrics: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := responseError(resp); err != nil {
+		return nil, nil, err
 	}
 
+	return parseMetricIndex(resp.Body)
+}
+
+// parseMetricNames collects the sorted metric names of an exposition
+func parseMetricNames(r io.Reader) ([]string, error) {
+	names, _, err := parseMetricIndex(r)
+	return names, err
+}
+
+// parseMetricIndex collects the sorted metric names of an exposition and the
+// metadata of their `# HELP` and `# TYPE` comments
+func parseMetricIndex(r io.Reader) ([]string, map[string]MetricMeta, error) {
 	metrics := make(map[string]bool)
-	scanner := bufio.NewScanner(resp.Body)
+	metadata := make(map[string]MetricMeta)
+	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
 		line := scanner.Text()
+		if isEOF(line) {
+			break
+		}
 
-		// Skip comments and empty lines
+		if name, kind, text, ok := parseMetaLine(line); ok {
+			meta := metadata[name]
+			if kind == "TYPE" {
+				meta.Type = text
+			} else {
+				meta.Help = text
+			}
+			metadata[name] = meta
+			continue
+		}
+
+		// Skip other comments and empty lines
 		if strings.HasPrefix(line, "#") || len(strings.TrimSpace(line)) == 0 {
 			continue
 		}
@@ -38,6 +372,10 @@ func fetchAllMetrics(url string) ([]string, error) {
 		}
 	}
 
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read metrics: %w", err)
+	}
+
 	// Convert map to sorted slice
 	result := make([]string, 0, len(metrics))
 	for name := range metrics {
@@ -45,25 +383,242 @@ func fetchAllMetrics(url string) ([]string, error) {
 	}
 	sort.Strings(result)
 
-	return result, nil
+	return result, metadata, nil
+}
+
+// limitMetrics keeps the sorted metric names containing filter, capped at max
+// (0 for unlimited). It also returns how many matching names were left out.
+func limitMetrics(names []string, filter string, max int) ([]string, int) {
+	if filter != "" {
+		var matching []string
+		for _, name := range names {
+			if strings.Contains(name, filter) {
+				matching = append(matching, name)
+			}
+		}
+		names = matching
+	}
+
+	if max > 0 && len(names) > max {
+		return names[:max], len(names) - max
+	}
+	return names, 0
+}
+
+// MetricMeta holds the TYPE and HELP metadata of a metric
+type MetricMeta struct {
+	Type string
+	Help string
+}
+
+// sampleSuffixes are the suffixes OpenMetrics adds to the samples of a type,
+// whose TYPE is declared for the name without them
+var sampleSuffixes = map[string]string{"_total": "counter", "_info": "info"}
+
+// lookupMeta returns the metadata of a metric, including OpenMetrics counters and
+// info metrics declared without the suffix of their samples
+func lookupMeta(metadata map[string]MetricMeta, name string) (MetricMeta, bool) {
+	if meta, ok := metadata[name]; ok {
+		return meta, true
+	}
+	for suffix, metricType := range sampleSuffixes {
+		if base, ok := strings.CutSuffix(name, suffix); ok {
+			if meta, ok := metadata[base]; ok && meta.Type == metricType {
+				return meta, true
+			}
+		}
+	}
+	return MetricMeta{}, false
+}
+
+// fetchAllMetadata fetches the TYPE and HELP metadata of all metrics from the endpoint
+func fetchAllMetadata(cfg fetchConfig, url string) (map[string]MetricMeta, error) {
+	resp, err := cfg.do(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch metrics: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := responseError(resp); err != nil {
+		return nil, err
+	}
+
+	return parseMetadata(resp.Body)
+}
+
+// parseMetadata collects the TYPE and HELP metadata of all metrics of an exposition
+func parseMetadata(r io.Reader) (map[string]MetricMeta, error) {
+	_, metadata, err := parseMetricIndex(r)
+	return metadata, err
+}
+
+// parseMetaLine parses a `# HELP name text` or `# TYPE name type` comment line
+func parseMetaLine(line string) (name, kind, text string, ok bool) {
+	rest, found := strings.CutPrefix(line, "# ")
+	if !found {
+		return "", "", "", false
+	}
+
+	kind, rest, found = strings.Cut(rest, " ")
+	if !found || (kind != "HELP" && kind != "TYPE") {
+		return "", "", "", false
+	}
+
+	name, text, _ = strings.Cut(strings.TrimLeft(rest, " "), " ")
+	if name == "" {
+		return "", "", "", false
+	}
+
+	text = strings.TrimSpace(text)
+	if kind == "HELP" {
+		// HELP text escapes backslashes and line feeds
+		text = strings.NewReplacer(`\\`, `\`, `\n`, "\n").Replace(text)
+	}
+
+	return name, kind, text, true
+}
+
+// fetchAllMetricValues fetches the values of all series grouped by metric name
+func fetchAllMetricValues(cfg fetchConfig, url string) (map[string][]float64, error) {
+	resp, err := cfg.do(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch metrics: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := responseError(resp); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string][]float64)
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if isEOF(line) {
+			break
+		}
+
+		// Skip comments and empty lines
+		if strings.HasPrefix(line, "#") || len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		name, value, ok := parseMetricLine(line)
+		if ok {
+			values[name] = append(values[name], value)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read metrics: %w", err)
+	}
+
+	return values, nil
+}
+
+// mostActiveMetric picks the metric whose series carry the most non-zero and
+// distinct values, preferring the alphabetically first one on ties
+func mostActiveMetric(values map[string][]float64) string {
+	best := ""
+	bestScore := -1
+	for name, vals := range values {
+		distinct := make(map[float64]bool)
+		nonZero := 0
+		for _, v := range vals {
+			if v != 0 {
+				nonZero++
+			}
+			distinct[v] = true
+		}
+		score := nonZero + len(distinct) - 1
+
+		if score > bestScore || (score == bestScore && name < best) {
+			best = name
+			bestScore = score
+		}
+	}
+	return best
+}
+
+// metricMatch decides which base names belong to the requested metric
+type metricMatch string
+
+const (
+	matchExact  metricMatch = "exact"  // Base name equals the metric name
+	matchPrefix metricMatch = "prefix" // Base name starts with the metric name
+	matchRegex  metricMatch = "regex"  // Base name fully matches the metric name as regular expression
+)
+
+// metricNames splits a comma-separated metric name into the metrics overlaid on one chart
+func metricNames(metricName string) []string {
+	var names []string
+	for _, name := range strings.Split(metricName, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// matcher returns a function reporting whether a base name belongs to the metric.
+// An empty mode matches exactly. Exact and prefix matching accept a
+// comma-separated list of names, regular expressions can use alternation.
+func (mm metricMatch) matcher(metricName string) (func(string) bool, error) {
+	names := metricNames(metricName)
+	if len(names) == 0 {
+		names = []string{metricName}
+	}
+	switch mm {
+	case matchExact, "":
+		return func(baseName string) bool { return slices.Contains(names, baseName) }, nil
+	case matchPrefix:
+		return func(baseName string) bool {
+			return slices.ContainsFunc(names, func(name string) bool { return strings.HasPrefix(baseName, name) })
+		}, nil
+	case matchRegex:
+		re, err := regexp.Compile("^(?:" + metricName + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid metric pattern: %w", err)
+		}
+		return re.MatchString, nil
+	default:
+		return nil, fmt.Errorf("invalid metric match %q (expected exact, prefix or regex)", string(mm))
+	}
 }
 
 // fetchAllMetricSeries fetches all series for a specific metric from the Prometheus endpoint
-func fetchAllMetricSeries(url, metricName string) ([]MetricSample, error) {
-	resp, err := http.Get(url)
+func fetchAllMetricSeries(cfg fetchConfig, url, metricName string, match metricMatch) ([]MetricSample, error) {
+	if cfg.query != "" {
+		return fetchQuery(cfg, url, cfg.query)
+	}
+
+	resp, err := cfg.do(url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch metrics: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	if err := responseError(resp); err != nil {
+		return nil, err
+	}
+
+	return parseMetricSeries(resp.Body, metricName, match)
+}
+
+// parseMetricSeries collects all series of a specific metric from an exposition
+func parseMetricSeries(r io.Reader, metricName string, match metricMatch) ([]MetricSample, error) {
+	matches, err := match.matcher(metricName)
+	if err != nil {
+		return nil, err
 	}
 
 	var samples []MetricSample
-	scanner := bufio.NewScanner(resp.Body)
+	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
 		line := scanner.Text()
+		if isEOF(line) {
+			break
+		}
 
 		// Skip comments and empty lines
 		if strings.HasPrefix(line, "#") || len(strings.TrimSpace(line)) == 0 {
@@ -71,12 +626,11 @@ func fetchAllMetricSeries(url, metricName string) ([]MetricSample, error) {
 		}
 
 		// Parse metric line
-		parts := strings.Fields(line)
-		if len(parts) < 2 {
+		fullName, fields, ok := splitMetricLine(line)
+		if !ok {
 			continue
 		}
 
-		fullName := parts[0]
 		baseName := fullName
 
 		// Extract base name if labels present
@@ -85,12 +639,12 @@ func fetchAllMetricSeries(url, metricName string) ([]MetricSample, error) {
 		}
 
 		// Check if this is the metric we're looking for
-		if baseName != metricName {
+		if !matches(baseName) {
 			continue
 		}
 
 		// Parse value
-		valueStr := parts[1]
+		valueStr := fields[0]
 		val, err := strconv.ParseFloat(valueStr, 64)
 		if err != nil {
 			continue
@@ -101,12 +655,18 @@ func fetchAllMetricSeries(url, metricName string) ([]MetricSample, error) {
 			fullName = fullName + "{}"
 		}
 
+		_, labels := parseLabels(fullName)
 		samples = append(samples, MetricSample{
 			FullName: fullName,
+			Labels:   labels,
 			Value:    val,
 		})
 	}
 
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read metrics: %w", err)
+	}
+
 	if len(samples) == 0 {
 		return nil, fmt.Errorf("metric %q not found", metricName)
 	}
@@ -114,19 +674,27 @@ func fetchAllMetricSeries(url, metricName string) ([]MetricSample, error) {
 	return samples, nil
 }
 
+// openMetricsEOF marks the end of an OpenMetrics exposition
+const openMetricsEOF = "# EOF"
+
+// isEOF reports whether the line ends an OpenMetrics exposition
+func isEOF(line string) bool {
+	return strings.TrimSpace(line) == openMetricsEOF
+}
+
 // parseMetricLine parses a single Prometheus metric line
 func parseMetricLine(line string) (name string, value float64, ok bool) {
 	// Handle metric with labels: metric_name{label="value"} 123.45
 	// Handle metric without labels: metric_name 123.45
 	// Handle optional timestamp at the end: metric_name{label="value"} 123.45 1627847261
 
-	parts := strings.Fields(line)
-	if len(parts) < 2 {
+	fullName, fields, ok := splitMetricLine(line)
+	if !ok {
 		return "", 0, false
 	}
 
-	// second field is the value (sometimes timestamp follows, but we ignore it)
-	valueStr := parts[1]
+	// first field after the name is the value (sometimes timestamp follows, but we ignore it)
+	valueStr := fields[0]
 
 	// Check if second to last might be the value (if timestamp is present)
 	val, err := strconv.ParseFloat(valueStr, 64)
@@ -135,7 +703,7 @@ func parseMetricLine(line string) (name string, value float64, ok bool) {
 	}
 
 	// Extract metric name (everything before the space and value)
-	name = parts[0]
+	name = fullName
 	// If there are labels, extract just the base name for matching
 	if before, _, ok0 := strings.Cut(name, "{"); ok0 {
 		return before, val, true
@@ -143,3 +711,50 @@ func parseMetricLine(line string) (name string, value float64, ok bool) {
 
 	return name, val, true
 }
+
+// splitMetricLine splits a metric line into the full series name (including
+// labels) and the remaining whitespace separated fields. Label values may
+// contain spaces or tabs, so a name with labels ends at the closing brace.
+func splitMetricLine(line string) (fullName string, fields []string, ok bool) {
+	line = strings.TrimSpace(line)
+
+	end := strings.IndexAny(line, " \t")
+	if brace := strings.Index(line, "{"); brace != -1 && (end == -1 || brace < end) {
+		closing := labelsEnd(line, brace)
+		if closing == -1 {
+			return "", nil, false
+		}
+		end = closing + 1
+	}
+	if end == -1 {
+		return "", nil, false
+	}
+
+	// OpenMetrics exemplars follow the value after a " # "
+	rest, _, _ := strings.Cut(line[end:], " # ")
+	fields = strings.Fields(rest)
+	if len(fields) == 0 {
+		return "", nil, false
+	}
+
+	return line[:end], fields, true
+}
+
+// labelsEnd returns the index of the brace closing the label set that starts
+// at the given index, ignoring braces inside quoted label values
+func labelsEnd(line string, start int) int {
+	inQuotes := false
+	for i := start + 1; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '"':
+			inQuotes = !inQuotes
+		case '}':
+			if !inQuotes {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// plainIndicators tell series apart in the legend when colors are disabled
+var plainIndicators = []string{"*", "+", "o", "x", "#", "@", "%", "&", "=", "~"}
+
+// colorsDisabled is set once colors are turned off by --no-color or NO_COLOR
+var colorsDisabled bool
+
+// noColorRequested reports whether plain output was asked for, following https://no-color.org
+func noColorRequested(flag bool) bool {
+	return flag || os.Getenv("NO_COLOR") != ""
+}
+
+// disableColors renders all styles without colors
+func disableColors() {
+	lipgloss.SetColorProfile(termenv.Ascii)
+	colorsDisabled = true
+}
+
+// seriesIndicator returns the legend marker of a series: a square in its color,
+// or a character of its own when colors are disabled
+func seriesIndicator(color lipgloss.Color, colorIdx int) string {
+	if colorsDisabled {
+		return plainIndicators[colorIdx%len(plainIndicators)]
+	}
+	return lipgloss.NewStyle().Foreground(color).Render("■")
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestNoColorRequested(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	if noColorRequested(false) {
+		t.Fatal("expected colors without flag and variable")
+	}
+	if !noColorRequested(true) {
+		t.Fatal("expected --no-color to disable colors")
+	}
+	t.Setenv("NO_COLOR", "1")
+	if !noColorRequested(false) {
+		t.Fatal("expected NO_COLOR to disable colors")
+	}
+}
+
+func TestSeriesIndicatorWithoutColors(t *testing.T) {
+	defer func() { colorsDisabled = false }()
+	colorsDisabled = true
+
+	seen := make(map[string]bool)
+	for i := range len(plainIndicators) {
+		indicator := seriesIndicator("46", i)
+		if seen[indicator] {
+			t.Fatalf("expected distinct indicators, got %q twice", indicator)
+		}
+		seen[indicator] = true
+	}
+}
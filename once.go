@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// runOnce prints a single snapshot of the metric's series without starting the
+// TUI. Series of URLs that could be scraped are printed even if others failed.
+func runOnce(cfg fetchConfig, urls []string, metricName string, match metricMatch, dups dupPolicy, by []string, w io.Writer) error {
+	samples, err := fetchSources(cfg, urls, metricName, match)
+	samples = dedupeSamples(samples, dups)
+	if len(by) > 0 {
+		samples = collapseSeries(samples, by)
+	}
+	for _, sample := range samples {
+		name := strings.TrimSuffix(sample.FullName, "{}")
+		fmt.Fprintf(w, "%s %s\n", name, strconv.FormatFloat(sample.Value, 'g', -1, 64))
+	}
+	if err != nil {
+		return fmt.Errorf("error fetching metrics: %w", err)
+	}
+	return nil
+}
+
+// listMetrics prints the names of all metrics of the endpoint, one per line,
+// followed by their TYPE and HELP separated by tabs where the endpoint has them
+func listMetrics(cfg fetchConfig, url string, w io.Writer) error {
+	names, metadata, err := fetchMetricIndex(cfg, url)
+	if err != nil {
+		return fmt.Errorf("error fetching metrics: %w", err)
+	}
+	for _, name := range names {
+		meta, ok := lookupMeta(metadata, name)
+		if !ok {
+			fmt.Fprintln(w, name)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", name, meta.Type, meta.Help)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRunOnce(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("up{job=\"api\"} 1\nup 0\nbuild_info 1\n"))
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	if err := runOnce(fetchConfig{}, []string{server.URL}, "up", matchExact, dupLast, nil, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "up{job=\"api\"} 1\nup 0\n"; out.String() != want {
+		t.Fatalf("expected %q, got %q", want, out.String())
+	}
+
+	out.Reset()
+	if err := runOnce(fetchConfig{}, []string{server.URL}, "missing", matchExact, dupLast, nil, &out); err == nil {
+		t.Fatal("expected an error for a missing metric")
+	}
+}
+
+func TestListMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("# HELP up Whether the target is up.\n# TYPE up gauge\nup 1\nbuild_info 1\n"))
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	if err := listMetrics(fetchConfig{}, server.URL, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "build_info\nup\tgauge\tWhether the target is up.\n"; out.String() != want {
+		t.Fatalf("expected %q, got %q", want, out.String())
+	}
+}
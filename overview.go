@@ -0,0 +1,98 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/NimbleMarkets/ntcharts/canvas/runes"
+	"github.com/NimbleMarkets/ntcharts/linechart/timeserieslinechart"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+)
+
+// overviewHeight is the height of the overview strip including its brush
+const overviewHeight = 5
+
+var brushStyle = lipgloss.NewStyle().Foreground(accentColor)
+
+// newOverviewChart creates the small chart that shows the full history
+func newOverviewChart(width int) timeserieslinechart.Model {
+	return timeserieslinechart.New(width, overviewHeight-1,
+		timeserieslinechart.WithAxesStyles(axisStyle, labelStyle),
+		timeserieslinechart.WithStyle(graphStyle),
+		timeserieslinechart.WithLineStyle(runes.ThinLineStyle),
+		timeserieslinechart.WithXYSteps(0, 0),
+	)
+}
+
+// redrawOverview re-pushes the full history of all plotted lines into the overview
+func (m *Model) redrawOverview() {
+	m.overview.ClearAllData()
+	m.overview.Clear()
+
+	minTime := time.Unix(int64(m.chart.MinX()), 0)
+	maxTime := time.Unix(int64(math.Ceil(m.chart.MaxX())), 0)
+	m.overview.SetTimeRange(minTime, maxTime)
+	m.overview.SetViewTimeRange(minTime, maxTime)
+	m.overview.SetYRange(m.chart.MinY(), m.chart.MaxY())
+	m.overview.SetViewYRange(m.chart.MinY(), m.chart.MaxY())
+
+	for _, line := range m.chartLines() {
+		style := lipgloss.NewStyle().Foreground(m.seriesColors[line.colorIdx%len(m.seriesColors)])
+		m.overview.SetDataSetStyle(line.name, style)
+		m.overview.SetDataSetLineStyle(line.name, runes.ThinLineStyle)
+		for _, point := range line.points {
+			m.overview.PushDataSet(line.name, point)
+		}
+	}
+
+	m.overview.DrawXYAxisAndLabel()
+	m.overview.DrawAll()
+}
+
+// brushColumns maps the viewed range onto the columns of a graph of the given width
+func brushColumns(minX, maxX, viewMinX, viewMaxX float64, width int) (start, end int) {
+	span := maxX - minX
+	if span <= 0 || width <= 0 {
+		return 0, width
+	}
+
+	start = int((viewMinX - minX) / span * float64(width))
+	end = int(math.Ceil((viewMaxX - minX) / span * float64(width)))
+	start = max(0, min(start, width-1))
+	end = max(start+1, min(end, width))
+	return start, end
+}
+
+// overviewView renders the overview strip with the brush marking the viewed range below it
+func (m *Model) overviewView() string {
+	width := m.overview.GraphWidth()
+	start, end := brushColumns(m.chart.MinX(), m.chart.MaxX(), m.chart.ViewMinX(), m.chart.ViewMaxX(), width)
+
+	brush := strings.Repeat(" ", m.overview.Width()-width) +
+		axisStyle.Render(strings.Repeat("─", start)) +
+		brushStyle.Render(strings.Repeat("█", end-start)) +
+		axisStyle.Render(strings.Repeat("─", width-end))
+
+	return lipgloss.JoinVertical(lipgloss.Left, m.overview.View(), zone.Mark("overview-brush", brush))
+}
+
+// moveBrush centers the chart's view on the given column of the brush, keeping its width
+func (m *Model) moveBrush(msg tea.MouseMsg) {
+	x, _ := zone.Get("overview-brush").Pos(msg)
+	width := m.overview.GraphWidth()
+	col := x - (m.overview.Width() - width)
+	if col < 0 || col >= width {
+		return
+	}
+
+	minX, maxX := m.chart.MinX(), m.chart.MaxX()
+	viewSpan := m.chart.ViewMaxX() - m.chart.ViewMinX()
+	center := minX + (float64(col)+0.5)/float64(width)*(maxX-minX)
+
+	start := math.Max(minX, math.Min(center-viewSpan/2, maxX-viewSpan))
+	m.chart.SetViewTimeRange(time.Unix(int64(start), 0), time.Unix(int64(start+viewSpan), 0))
+	m.drawChart()
+}
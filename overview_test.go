@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestBrushColumns(t *testing.T) {
+	tests := []struct {
+		name                           string
+		minX, maxX, viewMinX, viewMaxX float64
+		width, wantStart, wantEnd      int
+	}{
+		{"full range", 0, 100, 0, 100, 50, 0, 50},
+		{"second half", 0, 100, 50, 100, 50, 25, 50},
+		{"narrow range keeps a column", 0, 1000, 500, 501, 10, 5, 6},
+		{"empty range", 10, 10, 10, 10, 20, 0, 20},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := brushColumns(tt.minX, tt.maxX, tt.viewMinX, tt.viewMaxX, tt.width)
+			if start != tt.wantStart || end != tt.wantEnd {
+				t.Fatalf("expected columns %d-%d, got %d-%d", tt.wantStart, tt.wantEnd, start, end)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// waitingForData reports whether nothing was plotted yet, so the chart would be empty
+func (m *Model) waitingForData() bool {
+	return len(m.dataHistory) == 0
+}
+
+// placeholderView fills the chart area until the first series arrive, either
+// with what is being waited for or with the error of the failed scrape
+func (m *Model) placeholderView() string {
+	target := redactURL(m.url)
+	var text string
+	switch {
+	case m.err != nil:
+		errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
+		text = errStyle.Render(fmt.Sprintf("⚠️  %v", m.err)) + "\n\n" +
+			helpStyle.Render(m.retryText(time.Now()))
+	case !m.lastUpdate.IsZero():
+		text = labelStyle.Render(fmt.Sprintf("No series of %s found at %s yet…", m.metricName, target))
+	default:
+		text = labelStyle.Render(fmt.Sprintf("Connecting to %s…", target))
+	}
+	text = lipgloss.NewStyle().MaxWidth(m.width).Align(lipgloss.Center).Render(text)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, text)
+}
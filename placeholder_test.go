@@ -0,0 +1,32 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	zone "github.com/lrstanley/bubblezone"
+)
+
+func TestPlaceholderView(t *testing.T) {
+	zone.NewGlobal()
+	m := NewModel("http://localhost:9100/metrics", "up", 5*time.Second)
+
+	if view := m.View(); !strings.Contains(view, "Connecting to http://localhost:9100/metrics") {
+		t.Fatalf("expected the connecting placeholder, got:\n%s", view)
+	}
+
+	model, _ := m.Update(MetricsMsg{Err: errors.New("connection refused")})
+	m = model.(Model)
+	view := m.View()
+	if !strings.Contains(view, "connection refused") || !strings.Contains(view, "Retry 1 in 1s") {
+		t.Fatalf("expected the error with the retry interval, got:\n%s", view)
+	}
+
+	model, _ = m.Update(MetricsMsg{Samples: []MetricSample{{FullName: "up", Value: 1}}, Time: time.Now()})
+	m = model.(Model)
+	if view := m.View(); strings.Contains(view, "Connecting to") || strings.Contains(view, "Retry ") {
+		t.Fatalf("expected the chart once data arrived, got:\n%s", view)
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/pflag"
+)
+
+// profile is a named set of settings, e.g. for one environment
+type profile struct {
+	URL   string         `json:"url"`   // Endpoint to scrape unless a URL is given on the command line
+	Flags map[string]any `json:"flags"` // Values of command line flags by flag name, without dashes
+}
+
+// configFile is the layout of the config file
+type configFile struct {
+	Profiles map[string]profile `json:"profiles"`
+}
+
+// defaultConfigPath returns the location of the config file in the user's config directory
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "slashmetrics", "config.json")
+}
+
+// loadProfiles reads the profiles of a config file. A missing file has no profiles.
+func loadProfiles(path string) (map[string]profile, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var cfg configFile
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return cfg.Profiles, nil
+}
+
+// profileNames returns the names of all profiles in order
+func profileNames(profiles map[string]profile) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// apply sets the flags of the profile, skipping the ones given on the command line
+func (p profile) apply(flags *pflag.FlagSet, explicit map[string]bool) error {
+	for name, value := range p.Flags {
+		flag := flags.Lookup(name)
+		if flag == nil {
+			return fmt.Errorf("unknown flag %q", name)
+		}
+		if explicit[name] {
+			continue
+		}
+
+		// Lists set repeatable and comma separated flags one value at a time
+		values, isList := value.([]any)
+		if !isList {
+			values = []any{value}
+		}
+		for _, v := range values {
+			if err := flags.Set(name, profileValue(v)); err != nil {
+				return fmt.Errorf("invalid value for flag %q: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// profileValue formats a JSON value as it would be given on the command line
+func profileValue(v any) string {
+	if f, ok := v.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// resetFlags restores the defaults of all flags not given on the command line,
+// so the settings of one profile don't leak into the next
+func resetFlags(flags *pflag.FlagSet, explicit map[string]bool) {
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if explicit[flag.Name] || !flag.Changed {
+			return
+		}
+		if list, ok := flag.Value.(pflag.SliceValue); ok {
+			_ = list.Replace(nil)
+		} else {
+			_ = flag.Value.Set(flag.DefValue)
+		}
+		flag.Changed = false
+	})
+}
+
+// explicitFlags returns the names of all flags given on the command line
+func explicitFlags(flags *pflag.FlagSet) map[string]bool {
+	explicit := make(map[string]bool)
+	flags.Visit(func(flag *pflag.Flag) {
+		explicit[flag.Name] = true
+	})
+	return explicit
+}
+
+// switchProfile quits the program to start over with the profile after the active one
+func (m *Model) switchProfile() tea.Cmd {
+	if len(m.profiles) == 0 {
+		m.status = "No profiles in the config file"
+		return nil
+	}
+	idx := slices.Index(m.profiles, m.profile)
+	m.nextProfile = m.profiles[(idx+1)%len(m.profiles)]
+	return tea.Quit
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+func TestLoadProfiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	config := `{"profiles": {
+		"prod": {"url": "https://prod/metrics", "flags": {"metric": "up", "max-points": 500, "header": ["A: 1", "B: 2"]}},
+		"dev": {"url": "http://localhost:9090/metrics"}
+	}}`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	profiles, err := loadProfiles(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := profileNames(profiles); !reflect.DeepEqual(got, []string{"dev", "prod"}) {
+		t.Fatalf("expected sorted profile names, got %v", got)
+	}
+	if profiles["prod"].URL != "https://prod/metrics" {
+		t.Fatalf("unexpected URL %q", profiles["prod"].URL)
+	}
+
+	if profiles, err := loadProfiles(filepath.Join(t.TempDir(), "missing.json")); err != nil || profiles != nil {
+		t.Fatalf("expected no profiles for a missing file, got %v (%v)", profiles, err)
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	var (
+		metric   string
+		points   int
+		interval time.Duration
+		header   []string
+	)
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.StringVar(&metric, "metric", "", "")
+	flags.IntVar(&points, "max-points", 1000, "")
+	flags.DurationVar(&interval, "interval", 2*time.Second, "")
+	flags.StringArrayVar(&header, "header", nil, "")
+	if err := flags.Parse([]string{"--interval", "5s"}); err != nil {
+		t.Fatal(err)
+	}
+	explicit := explicitFlags(flags)
+
+	p := profile{Flags: map[string]any{
+		"metric":     "up",
+		"max-points": float64(500),
+		"interval":   "1s",
+		"header":     []any{"A: 1", "B: 2"},
+	}}
+	if err := p.apply(flags, explicit); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if metric != "up" || points != 500 || !reflect.DeepEqual(header, []string{"A: 1", "B: 2"}) {
+		t.Fatalf("expected the profile values, got %q %d %v", metric, points, header)
+	}
+	if interval != 5*time.Second {
+		t.Fatalf("expected the command line to take precedence, got %v", interval)
+	}
+
+	resetFlags(flags, explicit)
+	if metric != "" || points != 1000 || len(header) != 0 || interval != 5*time.Second {
+		t.Fatalf("expected defaults after reset, got %q %d %v %v", metric, points, header, interval)
+	}
+
+	if err := (profile{Flags: map[string]any{"unknown": true}}).apply(flags, explicit); err == nil {
+		t.Fatal("expected an error for an unknown flag")
+	}
+}
+
+func TestSwitchProfile(t *testing.T) {
+	m := Model{profiles: []string{"dev", "prod"}, profile: "prod"}
+	if cmd := m.switchProfile(); cmd == nil || m.nextProfile != "dev" {
+		t.Fatalf("expected to wrap around to the first profile, got %q", m.nextProfile)
+	}
+
+	m = Model{}
+	if cmd := m.switchProfile(); cmd != nil || m.status == "" {
+		t.Fatal("expected a status line without profiles")
+	}
+}
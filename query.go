@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// queryMetric names the series of a --query, whose results don't share a metric name
+const queryMetric = "query"
+
+// queryAPIPath is the instant query endpoint of the Prometheus HTTP API
+const queryAPIPath = "/api/v1/query"
+
+// errQueryFailed is returned for queries the server rejected, e.g. for invalid syntax
+var errQueryFailed = errors.New("query failed")
+
+// queryResponse is the envelope of a Prometheus HTTP API response
+type queryResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+// queryValue is a [timestamp, "value"] pair of a query result
+type queryValue [2]any
+
+// float parses the value, which the API sends as string to keep NaN and Inf
+func (v queryValue) float() (float64, error) {
+	s, ok := v[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("invalid sample value %v", v[1])
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// queryURL returns the instant query URL of a Prometheus server for an expression
+func queryURL(server, query string) (string, error) {
+	u, err := url.Parse(server)
+	if err != nil {
+		return "", fmt.Errorf("invalid server URL: %w", err)
+	}
+	if !strings.HasSuffix(u.Path, queryAPIPath) {
+		u.Path = strings.TrimSuffix(u.Path, "/") + queryAPIPath
+	}
+	values := u.Query()
+	values.Set("query", query)
+	u.RawQuery = values.Encode()
+	return u.String(), nil
+}
+
+// querySeriesName builds the full series name of a query result from its labels
+func querySeriesName(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = fmt.Sprintf("%s=%q", key, labels[key])
+	}
+	return queryMetric + "{" + strings.Join(pairs, ",") + "}"
+}
+
+// fetchQuery evaluates a PromQL expression with the query API of a Prometheus server
+func fetchQuery(cfg fetchConfig, server, query string) ([]MetricSample, error) {
+	target, err := queryURL(server, query)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := cfg.do(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query: %w", err)
+	}
+	defer resp.Body.Close()
+
+	samples, err := parseQueryResult(resp.Body)
+	// Rejected queries come with an error status code, but their message explains more
+	if resp.StatusCode != http.StatusOK && !errors.Is(err, errQueryFailed) {
+		return nil, statusError(resp.StatusCode)
+	}
+	return samples, err
+}
+
+// parseQueryResult collects the samples of an instant vector or scalar query result
+func parseQueryResult(r io.Reader) ([]MetricSample, error) {
+	var resp queryResponse
+	if err := json.NewDecoder(r).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("invalid query response: %w", err)
+	}
+	if resp.Status != "success" {
+		return nil, fmt.Errorf("%w: %s", errQueryFailed, resp.Error)
+	}
+
+	switch resp.Data.ResultType {
+	case "vector":
+		var result []struct {
+			Metric map[string]string `json:"metric"`
+			Value  queryValue        `json:"value"`
+		}
+		if err := json.Unmarshal(resp.Data.Result, &result); err != nil {
+			return nil, fmt.Errorf("invalid query result: %w", err)
+		}
+		samples := make([]MetricSample, 0, len(result))
+		for _, r := range result {
+			value, err := r.Value.float()
+			if err != nil {
+				return nil, fmt.Errorf("invalid query result: %w", err)
+			}
+			fullName := querySeriesName(r.Metric)
+			_, labels := parseLabels(fullName)
+			samples = append(samples, MetricSample{FullName: fullName, Labels: labels, Value: value})
+		}
+		return samples, nil
+	case "scalar":
+		var result queryValue
+		if err := json.Unmarshal(resp.Data.Result, &result); err != nil {
+			return nil, fmt.Errorf("invalid query result: %w", err)
+		}
+		value, err := result.float()
+		if err != nil {
+			return nil, fmt.Errorf("invalid query result: %w", err)
+		}
+		return []MetricSample{{FullName: queryMetric + "{}", Labels: map[string]string{}, Value: value}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported query result type %q (expected vector or scalar)", resp.Data.ResultType)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestQueryURL(t *testing.T) {
+	tests := []struct {
+		server string
+		want   string
+	}{
+		{"http://prom:9090", "http://prom:9090/api/v1/query?query=up"},
+		{"http://prom:9090/", "http://prom:9090/api/v1/query?query=up"},
+		{"http://host/prometheus", "http://host/prometheus/api/v1/query?query=up"},
+		{"http://prom:9090/api/v1/query", "http://prom:9090/api/v1/query?query=up"},
+	}
+	for _, tt := range tests {
+		got, err := queryURL(tt.server, "up")
+		if err != nil || got != tt.want {
+			t.Errorf("queryURL(%q) = %q, %v, want %q", tt.server, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseQueryResult(t *testing.T) {
+	vector := `{"status":"success","data":{"resultType":"vector","result":[
+		{"metric":{"job":"api","code":"200"},"value":[1700000000.5,"1.5"]},
+		{"metric":{"job":"api","code":"500"},"value":[1700000000.5,"NaN"]}]}}`
+	samples, err := parseQueryResult(strings.NewReader(vector))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(samples) != 2 || samples[0].FullName != `query{code="200",job="api"}` || samples[0].Value != 1.5 ||
+		samples[0].Labels["job"] != "api" || !math.IsNaN(samples[1].Value) {
+		t.Fatalf("unexpected samples %+v", samples)
+	}
+
+	scalar := `{"status":"success","data":{"resultType":"scalar","result":[1700000000,"42"]}}`
+	samples, err = parseQueryResult(strings.NewReader(scalar))
+	if err != nil || len(samples) != 1 || samples[0].FullName != "query{}" || samples[0].Value != 42 {
+		t.Fatalf("unexpected scalar samples %+v (%v)", samples, err)
+	}
+
+	matrix := `{"status":"success","data":{"resultType":"matrix","result":[]}}`
+	if _, err := parseQueryResult(strings.NewReader(matrix)); err == nil {
+		t.Fatal("expected an error for range vectors")
+	}
+}
+
+func TestFetchQuery(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != queryAPIPath {
+			http.NotFound(w, r)
+			return
+		}
+		query = r.URL.Query().Get("query")
+		if strings.Contains(query, "[") && !strings.Contains(query, "]") {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"unclosed left bracket"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000,"3"]}]}}`))
+	}))
+	defer server.Close()
+
+	cfg := fetchConfig{query: "sum(rate(http_requests_total[5m]))"}
+	samples, err := fetchAllMetricSeries(cfg, server.URL, queryMetric, matchExact)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query != cfg.query || len(samples) != 1 || samples[0].FullName != "query{}" || samples[0].Value != 3 {
+		t.Fatalf("unexpected samples %+v for query %q", samples, query)
+	}
+
+	cfg.query = "rate(http_requests_total[5m"
+	if _, err := fetchAllMetricSeries(cfg, server.URL, queryMetric, matchExact); err == nil || !strings.Contains(err.Error(), "unclosed left bracket") {
+		t.Fatalf("expected the error of the server, got %v", err)
+	}
+}
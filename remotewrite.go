@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/NimbleMarkets/ntcharts/linechart/timeserieslinechart"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/golang/snappy"
+)
+
+// RemoteWriteMsg reports the result of exporting the captured data
+type RemoteWriteMsg struct {
+	Target string // URL or file the data was written to
+	Err    error
+}
+
+// appendProtoTag appends the key of a protobuf field
+func appendProtoTag(b []byte, field, wireType int) []byte {
+	return binary.AppendUvarint(b, uint64(field<<3|wireType))
+}
+
+// appendProtoBytes appends a length delimited protobuf field
+func appendProtoBytes(b []byte, field int, data []byte) []byte {
+	b = appendProtoTag(b, field, 2)
+	b = binary.AppendUvarint(b, uint64(len(data)))
+	return append(b, data...)
+}
+
+// encodeWriteRequest serializes the history of all series as a Prometheus
+// remote-write WriteRequest protobuf message
+func encodeWriteRequest(history map[string][]timeserieslinechart.TimePoint) []byte {
+	names := make([]string, 0, len(history))
+	for name := range history {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var req []byte
+	for _, fullName := range names {
+		baseName, _, _ := strings.Cut(fullName, "{")
+
+		// Remote-write expects labels sorted by name, including the metric name
+		labels := append(seriesLabels(fullName), [2]string{"__name__", baseName})
+		sort.Slice(labels, func(i, j int) bool { return labels[i][0] < labels[j][0] })
+
+		var ts []byte
+		for _, label := range labels {
+			var l []byte
+			l = appendProtoBytes(l, 1, []byte(label[0]))
+			l = appendProtoBytes(l, 2, []byte(label[1]))
+			ts = appendProtoBytes(ts, 1, l)
+		}
+		for _, point := range history[fullName] {
+			var s []byte
+			s = appendProtoTag(s, 1, 1)
+			s = binary.LittleEndian.AppendUint64(s, math.Float64bits(point.Value))
+			s = appendProtoTag(s, 2, 0)
+			s = binary.AppendUvarint(s, uint64(point.Time.UnixMilli()))
+			ts = appendProtoBytes(ts, 2, s)
+		}
+		req = appendProtoBytes(req, 1, ts)
+	}
+	return req
+}
+
+// exportRemoteWriteCmd returns a command that pushes the history to a
+// remote-write endpoint with the client, headers and credentials of the scrapes,
+// or writes it to a file in dir if no URL is given
+func exportRemoteWriteCmd(cfg fetchConfig, history map[string][]timeserieslinechart.TimePoint, metricName, remoteURL, dir string) tea.Cmd {
+	return func() tea.Msg {
+		body := snappy.Encode(nil, encodeWriteRequest(history))
+
+		if remoteURL == "" {
+			path := filepath.Join(dir, exportFileName(metricName, "pb.snappy"))
+			if err := os.WriteFile(path, body, 0o644); err != nil {
+				return RemoteWriteMsg{Target: path, Err: fmt.Errorf("failed to write export: %w", err)}
+			}
+			return RemoteWriteMsg{Target: path}
+		}
+
+		req, err := cfg.newRequest(http.MethodPost, remoteURL, bytes.NewReader(body), http.Header{
+			"Content-Encoding":                  {"snappy"},
+			"Content-Type":                      {"application/x-protobuf"},
+			"X-Prometheus-Remote-Write-Version": {"0.1.0"},
+		})
+		if err != nil {
+			return RemoteWriteMsg{Target: redactURL(remoteURL), Err: err}
+		}
+
+		resp, err := cfg.httpClient().Do(req)
+		if err != nil {
+			return RemoteWriteMsg{Target: redactURL(remoteURL), Err: fmt.Errorf("failed to push export: %w", err)}
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode/100 != 2 {
+			return RemoteWriteMsg{Target: redactURL(remoteURL), Err: statusError(resp.StatusCode)}
+		}
+		return RemoteWriteMsg{Target: redactURL(remoteURL)}
+	}
+}
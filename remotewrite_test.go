@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NimbleMarkets/ntcharts/linechart/timeserieslinechart"
+	"github.com/golang/snappy"
+)
+
+func TestEncodeWriteRequest(t *testing.T) {
+	history := map[string][]timeserieslinechart.TimePoint{
+		`up{}`: {{Time: time.UnixMilli(1), Value: 2}},
+	}
+
+	want := []byte{
+		0x0a, 0x1d, // timeseries
+		0x0a, 0x0e, // label
+		0x0a, 0x08, '_', '_', 'n', 'a', 'm', 'e', '_', '_',
+		0x12, 0x02, 'u', 'p',
+		0x12, 0x0b, // sample
+		0x09, 0, 0, 0, 0, 0, 0, 0, 0x40, // value 2.0
+		0x10, 0x01, // timestamp 1ms
+	}
+	if got := encodeWriteRequest(history); !bytes.Equal(got, want) {
+		t.Fatalf("expected %x, got %x", want, got)
+	}
+}
+
+func TestExportRemoteWritePush(t *testing.T) {
+	history := map[string][]timeserieslinechart.TimePoint{
+		`up{job="a"}`: {{Time: time.UnixMilli(1700000000000), Value: 1}},
+	}
+
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "snappy" || r.Header.Get("Content-Type") != "application/x-protobuf" ||
+			r.Header.Get("User-Agent") != "gateway-approved/1.0" || r.Header.Get("X-Scope-OrgID") != "team-a" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if user, password, ok := r.BasicAuth(); !ok || user != "alice" || password != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		received, _ = snappy.Decode(nil, body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	cfg := fetchConfig{
+		userAgent: "gateway-approved/1.0",
+		username:  "alice",
+		password:  "secret",
+		header:    http.Header{"X-Scope-Orgid": {"team-a"}},
+		client:    &http.Client{Timeout: time.Second},
+	}
+	msg := exportRemoteWriteCmd(cfg, history, "up", server.URL, "")().(RemoteWriteMsg)
+	if msg.Err != nil {
+		t.Fatalf("unexpected error: %v", msg.Err)
+	}
+	if !bytes.Equal(received, encodeWriteRequest(history)) {
+		t.Fatalf("expected the encoded history to be pushed")
+	}
+}
+
+func TestExportRemoteWriteFile(t *testing.T) {
+	history := map[string][]timeserieslinechart.TimePoint{
+		`up{job="a"}`: {{Time: time.UnixMilli(1700000000000), Value: 1}},
+	}
+	dir := t.TempDir()
+
+	msg := exportRemoteWriteCmd(fetchConfig{}, history, "up", "", dir)().(RemoteWriteMsg)
+	if msg.Err != nil {
+		t.Fatalf("unexpected error: %v", msg.Err)
+	}
+	if filepath.Dir(msg.Target) != dir {
+		t.Fatalf("expected the export in %s, got %s", dir, msg.Target)
+	}
+	body, err := os.ReadFile(msg.Target)
+	if err != nil {
+		t.Fatalf("failed to read export: %v", err)
+	}
+	if decoded, _ := snappy.Decode(nil, body); !bytes.Equal(decoded, encodeWriteRequest(history)) {
+		t.Fatalf("expected the encoded history to be written")
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	zone "github.com/lrstanley/bubblezone"
+	"github.com/spf13/cobra"
+)
+
+var (
+	replayMetricFlag string
+	replayDirCmd     = &cobra.Command{
+		Use:   "replay-dir <dir>",
+		Short: "Replay a directory of captured scrapes in timestamp order",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runReplay(args[0])
+		},
+	}
+)
+
+func init() {
+	replayDirCmd.Flags().StringVar(&replayMetricFlag, "metric", "", "The metric to visualize (if empty, the first metric will be chosen)")
+	rootCmd.AddCommand(replayDirCmd)
+}
+
+// replayFrame is a single captured scrape
+type replayFrame struct {
+	path string
+	time time.Time
+}
+
+// frameTimePattern finds the scrape time in a file name: an RFC 3339 time
+// (with - instead of : for file systems that don't allow them), a compact
+// date and time like 20240131-120000, or Unix seconds or milliseconds
+var frameTimePattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}[:-]\d{2}[:-]\d{2}(Z|[+-]\d{2}:\d{2})?|\d{8}[T-]?\d{6}Z?|\d{10,13}`)
+
+// frameClockDashes matches the clock of an RFC 3339 time written with dashes
+var frameClockDashes = regexp.MustCompile(`T(\d{2})-(\d{2})-(\d{2})`)
+
+// frameTimeLayouts are the layouts the matches of frameTimePattern are parsed with
+var frameTimeLayouts = []string{
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05",
+	"20060102T150405Z0700",
+	"20060102T150405",
+	"20060102-150405",
+	"20060102150405",
+}
+
+// frameTime parses the scrape time from a file name. Times without a zone are local.
+func frameTime(name string) (time.Time, bool) {
+	match := frameTimePattern.FindString(name)
+	if match == "" {
+		return time.Time{}, false
+	}
+
+	if len(match) == 10 || len(match) == 13 {
+		if n, err := strconv.ParseInt(match, 10, 64); err == nil {
+			if len(match) == 13 {
+				return time.UnixMilli(n), true
+			}
+			return time.Unix(n, 0), true
+		}
+	}
+
+	match = frameClockDashes.ReplaceAllString(match, "T$1:$2:$3")
+	for _, layout := range frameTimeLayouts {
+		if t, err := time.ParseInLocation(layout, match, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// listReplayFrames returns the scrape files of a directory in the order they
+// were scraped. Each frame is timestamped with the time in its file name, as
+// copying the files usually resets their modification time, which is only used
+// as a fallback. Frames scraped at the same time are ordered by name.
+func listReplayFrames(dir string) ([]replayFrame, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read replay directory: %w", err)
+	}
+
+	var frames []replayFrame
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		t, ok := frameTime(entry.Name())
+		if !ok {
+			info, err := entry.Info()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read replay file: %w", err)
+			}
+			t = info.ModTime()
+		}
+		frames = append(frames, replayFrame{path: filepath.Join(dir, entry.Name()), time: t})
+	}
+
+	// ReadDir sorts by name already, which breaks ties between equal times
+	sort.SliceStable(frames, func(i, j int) bool { return frames[i].time.Before(frames[j].time) })
+
+	return frames, nil
+}
+
+// openReplayFrame opens a scrape file, transparently decompressing gzipped files
+func openReplayFrame(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open replay file: %w", err)
+	}
+
+	// Detect gzip by its magic bytes rather than the file extension
+	r, err := gunzip(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to decompress replay file: %w", err)
+	}
+	return r, nil
+}
+
+// readReplayFrame reads the series of a metric from a scrape file
+func readReplayFrame(frame replayFrame, metricName string, match metricMatch) ([]MetricSample, error) {
+	r, err := openReplayFrame(frame.path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	samples, err := parseMetricSeries(r, metricName, match)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(frame.path), err)
+	}
+	return samples, nil
+}
+
+// replayCmd returns a command that feeds all frames to the model in order
+func replayCmd(frames []replayFrame, metricName string, match metricMatch) tea.Cmd {
+	cmds := make([]tea.Cmd, len(frames))
+	for i, frame := range frames {
+		cmds[i] = func() tea.Msg {
+			samples, err := readReplayFrame(frame, metricName, match)
+			return MetricsMsg{Samples: samples, Err: err, Time: frame.time}
+		}
+	}
+	return tea.Sequence(cmds...)
+}
+
+// replayMetricsCmd returns a command that lists the metrics of the last frame
+func replayMetricsCmd(frames []replayFrame) tea.Cmd {
+	return func() tea.Msg {
+		if len(frames) == 0 {
+			return MetricsListMsg{Err: fmt.Errorf("no scrape files found")}
+		}
+
+		r, err := openReplayFrame(frames[len(frames)-1].path)
+		if err != nil {
+			return MetricsListMsg{Err: err}
+		}
+		defer r.Close()
+
+		metrics, metadata, err := parseMetricIndex(r)
+		return MetricsListMsg{Metrics: metrics, Metadata: metadata, Err: err}
+	}
+}
+
+// replayMetadataCmd returns a command that reads the metadata of the last frame
+func replayMetadataCmd(frames []replayFrame) tea.Cmd {
+	return func() tea.Msg {
+		if len(frames) == 0 {
+			return MetadataMsg{Err: fmt.Errorf("no scrape files found")}
+		}
+
+		r, err := openReplayFrame(frames[len(frames)-1].path)
+		if err != nil {
+			return MetadataMsg{Err: err}
+		}
+		defer r.Close()
+
+		metadata, err := parseMetadata(r)
+		return MetadataMsg{Metadata: metadata, Err: err}
+	}
+}
+
+func runReplay(dir string) error {
+	frames, err := listReplayFrames(dir)
+	if err != nil {
+		return err
+	}
+	if len(frames) == 0 {
+		return fmt.Errorf("no scrape files found in %s", dir)
+	}
+
+	selectedMetric := replayMetricFlag
+	if selectedMetric == "" {
+		msg := replayMetricsCmd(frames)().(MetricsListMsg)
+		if msg.Err != nil {
+			return fmt.Errorf("error reading metrics: %w", msg.Err)
+		}
+		if len(msg.Metrics) == 0 {
+			return fmt.Errorf("no metrics found in %s", dir)
+		}
+		selectedMetric = msg.Metrics[0]
+	}
+
+	zone.NewGlobal()
+
+	m := NewModel(dir, selectedMetric, intervalFlag)
+	m.replayFrames = frames
+
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseAllMotion())
+	if _, err := p.Run(); err != nil {
+		return err
+	}
+
+	return nil
+}
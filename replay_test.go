@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeReplayFile(t *testing.T, path, body string, gzipped bool, modTime time.Time) {
+	t.Helper()
+
+	data := []byte(body)
+	if gzipped {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write(data); err != nil {
+			t.Fatalf("failed to compress: %v", err)
+		}
+		gz.Close()
+		data = buf.Bytes()
+	}
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write replay file: %v", err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("failed to set modification time: %v", err)
+	}
+}
+
+func TestListReplayFrames(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Unix(1700000000, 0)
+
+	writeReplayFile(t, filepath.Join(dir, "b.prom"), "metric_a 2\n", false, start.Add(time.Minute))
+	writeReplayFile(t, filepath.Join(dir, "a.prom.gz"), "metric_a 1\n", true, start)
+	writeReplayFile(t, filepath.Join(dir, "c.prom"), "metric_a 3\n", false, start.Add(2*time.Minute))
+	if err := os.Mkdir(filepath.Join(dir, "nested"), 0o700); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+
+	frames, err := listReplayFrames(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(frames) != 3 {
+		t.Fatalf("expected 3 frames, got %d", len(frames))
+	}
+
+	for i, want := range []float64{1, 2, 3} {
+		samples, err := readReplayFrame(frames[i], "metric_a", matchExact)
+		if err != nil {
+			t.Fatalf("frame %d: unexpected error: %v", i, err)
+		}
+		if len(samples) != 1 || samples[0].Value != want {
+			t.Fatalf("frame %d: expected value %v, got %v", i, want, samples)
+		}
+		if !frames[i].time.Equal(start.Add(time.Duration(i) * time.Minute)) {
+			t.Fatalf("frame %d: unexpected time %v", i, frames[i].time)
+		}
+	}
+}
+
+func TestListReplayFramesTimestampedNames(t *testing.T) {
+	dir := t.TempDir()
+	// Copies get fresh modification times that don't match the scrape order
+	copied := time.Now()
+	writeReplayFile(t, filepath.Join(dir, "scrape-1700000060.prom"), "metric_a 2\n", false, copied)
+	writeReplayFile(t, filepath.Join(dir, "scrape-1700000000.prom"), "metric_a 1\n", false, copied.Add(time.Minute))
+
+	frames, err := listReplayFrames(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(frames) != 2 {
+		t.Fatalf("expected 2 frames, got %d", len(frames))
+	}
+	for i, want := range []int64{1700000000, 1700000060} {
+		if frames[i].time.Unix() != want {
+			t.Fatalf("frame %d: expected the time of the file name, got %v", i, frames[i].time)
+		}
+	}
+}
+
+func TestListReplayFramesMixedNames(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Unix(1700000000, 0)
+	writeReplayFile(t, filepath.Join(dir, "a-1700000120.prom"), "metric_a 3\n", false, time.Now())
+	writeReplayFile(t, filepath.Join(dir, "b-1700000000.prom"), "metric_a 1\n", false, time.Now())
+	writeReplayFile(t, filepath.Join(dir, "manual.prom"), "metric_a 2\n", false, start.Add(time.Minute))
+
+	frames, err := listReplayFrames(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, want := range []float64{1, 2, 3} {
+		samples, err := readReplayFrame(frames[i], "metric_a", matchExact)
+		if err != nil {
+			t.Fatalf("frame %d: unexpected error: %v", i, err)
+		}
+		if len(samples) != 1 || samples[0].Value != want {
+			t.Fatalf("frame %d: expected value %v, got %v", i, want, samples)
+		}
+		if i > 0 && frames[i].time.Before(frames[i-1].time) {
+			t.Fatalf("frame %d: expected increasing times, got %v after %v", i, frames[i].time, frames[i-1].time)
+		}
+	}
+}
+
+func TestFrameTime(t *testing.T) {
+	tests := []struct {
+		name string
+		want time.Time
+		ok   bool
+	}{
+		{"1700000000.prom", time.Unix(1700000000, 0), true},
+		{"scrape_1700000000123.txt.gz", time.UnixMilli(1700000000123), true},
+		{"2024-01-31T12:00:00Z.prom", time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC), true},
+		{"node-2024-01-31T12-00-00+02:00.prom", time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC), true},
+		{"20240131-120000.prom", time.Date(2024, 1, 31, 12, 0, 0, 0, time.Local), true},
+		{"20240131T120000Z.prom.gz", time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC), true},
+		{"scrape.prom", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := frameTime(tt.name)
+		if ok != tt.ok || !got.Equal(tt.want) {
+			t.Errorf("frameTime(%q) = %v, %v; want %v, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestReadReplayFrameMissingMetric(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scrape.prom")
+	writeReplayFile(t, path, "metric_a 1\n", false, time.Now())
+
+	if _, err := readReplayFrame(replayFrame{path: path}, "missing", matchExact); err == nil {
+		t.Fatalf("expected error when metric is missing")
+	}
+}
+
+func TestReplayMetricsCmd(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Unix(1700000000, 0)
+	writeReplayFile(t, filepath.Join(dir, "1.prom"), "metric_old 1\n", false, start)
+	writeReplayFile(t, filepath.Join(dir, "2.prom"), "metric_b 1\nmetric_a 2\n", true, start.Add(time.Minute))
+
+	frames, err := listReplayFrames(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msg := replayMetricsCmd(frames)().(MetricsListMsg)
+	if msg.Err != nil {
+		t.Fatalf("unexpected error: %v", msg.Err)
+	}
+	if len(msg.Metrics) != 2 || msg.Metrics[0] != "metric_a" || msg.Metrics[1] != "metric_b" {
+		t.Fatalf("expected metrics of the last frame, got %v", msg.Metrics)
+	}
+}
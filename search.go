@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// SearchIndexMsg carries every series of the endpoint for the global search
+type SearchIndexMsg struct {
+	Samples []MetricSample
+	Err     error
+}
+
+// fetchSearchIndexCmd scrapes all series of every metric once. The series are
+// named like on the chart, labeled with their source and collapsed to --by.
+func fetchSearchIndexCmd(cfg fetchConfig, urls []string, by []string) tea.Cmd {
+	return func() tea.Msg {
+		samples, err := fetchSources(cfg, urls, ".+", matchRegex)
+		if len(by) > 0 {
+			samples = collapseSeries(samples, by)
+		}
+		return SearchIndexMsg{Samples: samples, Err: err}
+	}
+}
+
+// searchMatches returns the series whose name or label values contain the search
+// text, ignoring case. Without search text nothing matches.
+func searchMatches(index []MetricSample, query string) []MetricSample {
+	if strings.TrimSpace(query) == "" {
+		return nil
+	}
+	var matches []MetricSample
+	for _, sample := range index {
+		if matchesSeriesFilter(sample.FullName, query) {
+			matches = append(matches, sample)
+		}
+	}
+	return matches
+}
+
+// openSearch starts the global search, scraping the series to search through
+func (m *Model) openSearch() tea.Cmd {
+	m.searching = true
+	m.searchQuery = ""
+	m.searchSelected = 0
+	m.searchIndex = nil
+	m.searchErr = nil
+	return fetchSearchIndexCmd(m.fetch, m.urls, m.seriesBy)
+}
+
+// updateSearch handles key presses in the global search
+func (m *Model) updateSearch(msg tea.KeyMsg) tea.Cmd {
+	matches := searchMatches(m.searchIndex, m.searchQuery)
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.searching = false
+	case tea.KeyEnter:
+		if m.searchSelected >= len(matches) {
+			return nil
+		}
+		return m.jumpToSeries(matches[m.searchSelected].FullName)
+	case tea.KeyUp:
+		m.searchSelected = max(m.searchSelected-1, 0)
+	case tea.KeyDown:
+		m.searchSelected = max(min(m.searchSelected+1, len(matches)-1), 0)
+	case tea.KeyBackspace:
+		if runes := []rune(m.searchQuery); len(runes) > 0 {
+			m.searchQuery = string(runes[:len(runes)-1])
+		}
+		m.searchSelected = 0
+	case tea.KeyRunes, tea.KeySpace:
+		m.searchQuery += string(msg.Runes)
+		m.searchSelected = 0
+	}
+	return nil
+}
+
+// jumpToSeries switches to the metric of a series found by the search, showing
+// only that series while the others of the metric start hidden
+func (m *Model) jumpToSeries(fullName string) tea.Cmd {
+	base, _, _ := strings.Cut(fullName, "{")
+	m.searching = false
+	m.switchMetric(base)
+	m.seriesMatch = regexp.MustCompile("^" + regexp.QuoteMeta(fullName) + "$")
+	return m.loadCmd()
+}
+
+// searchView renders the global search with the matching series
+func (m *Model) searchView() string {
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render("Search series of all metrics"))
+	sb.WriteString("\n\n")
+
+	switch {
+	case m.searchErr != nil:
+		sb.WriteString(listItemStyle.Render(fmt.Sprintf("Failed to scrape the series to search: %v", m.searchErr)))
+		sb.WriteString("\n\n")
+	case m.searchIndex == nil:
+		sb.WriteString(labelStyle.Render("Scraping all series…"))
+		sb.WriteString("\n\n")
+	default:
+		matches := searchMatches(m.searchIndex, m.searchQuery)
+		sb.WriteString(labelStyle.Render(fmt.Sprintf("Search: %s█ (%d of %d series)", m.searchQuery, len(matches), len(m.searchIndex))))
+		sb.WriteString("\n\n")
+
+		// Keep the selected match within the visible part of the list
+		maxVisible := max(m.termHeight-14, 3)
+		start := max(m.searchSelected-maxVisible+1, 0)
+		end := min(start+maxVisible, len(matches))
+		for i := start; i < end; i++ {
+			if i == m.searchSelected {
+				sb.WriteString(listSelectedItemStyle.Render("> " + matches[i].FullName))
+			} else {
+				sb.WriteString(listItemStyle.Render("  " + matches[i].FullName))
+			}
+			sb.WriteString("\n")
+		}
+		if end < len(matches) {
+			sb.WriteString(labelStyle.Render(fmt.Sprintf("+%d more, narrow down the search", len(matches)-end)))
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString(helpStyle.Render("Type to search names and label values, ↑↓ to pick, Enter to jump to the series, Esc to close"))
+	return sb.String()
+}
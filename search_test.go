@@ -0,0 +1,125 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	zone "github.com/lrstanley/bubblezone"
+)
+
+func TestSearchMatches(t *testing.T) {
+	index := []MetricSample{
+		{FullName: `http_requests_total{code="200",handler="/api"}`},
+		{FullName: `http_requests_total{code="500",handler="/api"}`},
+		{FullName: `grpc_server_handled_total{grpc_code="Unknown",status="500"}`},
+		{FullName: `up{}`},
+	}
+
+	if got := searchMatches(index, `status="500"`); len(got) != 1 || got[0].FullName != index[2].FullName {
+		t.Fatalf("expected the series with the label value, got %v", got)
+	}
+	if got := searchMatches(index, "500"); len(got) != 2 {
+		t.Fatalf("expected the series of both metrics, got %v", got)
+	}
+	if got := searchMatches(index, "  "); got != nil {
+		t.Fatalf("expected no matches without search text, got %v", got)
+	}
+}
+
+func TestJumpToSearchMatch(t *testing.T) {
+	zone.NewGlobal()
+	m := NewModel("http://localhost", "up", time.Second)
+	m.searching = true
+	m.searchIndex = []MetricSample{
+		{FullName: `http_requests_total{code="200"}`},
+		{FullName: `http_requests_total{code="500"}`},
+	}
+
+	for _, key := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune("code")},
+		{Type: tea.KeyDown},
+		{Type: tea.KeyEnter},
+	} {
+		model, _ := m.Update(key)
+		m = model.(Model)
+	}
+	if m.searching || m.metricName != "http_requests_total" {
+		t.Fatalf("expected to jump to the metric of the match, got %q", m.metricName)
+	}
+
+	model, _ := m.Update(MetricsMsg{Samples: []MetricSample{
+		{FullName: `http_requests_total{code="200"}`, Value: 1},
+		{FullName: `http_requests_total{code="500"}`, Value: 2},
+	}, Time: time.Now()})
+	m = model.(Model)
+	for _, series := range m.seriesList {
+		if series.checked != (series.name == `http_requests_total{code="500"}`) {
+			t.Fatalf("expected only the matched series to be shown, got %+v", m.seriesList)
+		}
+	}
+}
+
+func TestJumpToSearchMatchOfSources(t *testing.T) {
+	var urls []string
+	for range 2 {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("http_requests_total{code=\"200\",method=\"GET\"} 1\n" +
+				"http_requests_total{code=\"500\",method=\"GET\"} 2\n" +
+				"http_requests_total{code=\"500\",method=\"POST\"} 3\n" +
+				"up 1\n"))
+		}))
+		defer server.Close()
+		urls = append(urls, server.URL)
+	}
+	source := sourceHost(urls[1])
+
+	tests := []struct {
+		name string
+		by   []string
+		want string
+	}{
+		{"sources", nil, `http_requests_total{source="` + source + `",code="500",method="GET"}`},
+		{"by", []string{"source", "code"}, `http_requests_total{source="` + source + `",code="500"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zone.NewGlobal()
+			m := NewModel(urls[0], "up", time.Second)
+			m.urls = urls
+			m.seriesBy = tt.by
+
+			model, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+			model, _ = model.Update(cmd())
+			model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(source + `",code="500`)})
+			m = model.(Model)
+			if matches := searchMatches(m.searchIndex, m.searchQuery); len(matches) == 0 || matches[0].FullName != tt.want {
+				t.Fatalf("expected %s to match first, got %v (%v)", tt.want, matches, m.searchErr)
+			}
+			model, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+			m = model.(Model)
+
+			// The scrapes of the metric show only the series jumped to
+			for i := range 2 {
+				msg := fetchMetricCmd(m.fetch, m.urls, m.metricName, m.metricMatch)().(MetricsMsg)
+				msg.Time = time.Unix(int64(1000+i), 0)
+				model, _ = m.Update(msg)
+				m = model.(Model)
+			}
+			shown := 0
+			for _, series := range m.seriesList {
+				if series.checked {
+					shown++
+					if series.name != tt.want {
+						t.Fatalf("expected only %s to be shown, got %+v", tt.want, m.seriesList)
+					}
+				}
+			}
+			if shown != 1 || len(m.seriesList) < 2 {
+				t.Fatalf("expected %s to be shown among the other series, got %+v", tt.want, m.seriesList)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// capNewSeries hides the newly discovered series with the lowest values while
+// more than max series are shown, so only the top series are drawn by default.
+// Series shown before, e.g. toggled by the user, are left as they are.
+func capNewSeries(series []seriesItem, added map[string]float64, max int) {
+	if max <= 0 || len(added) == 0 {
+		return
+	}
+
+	shown := 0
+	var candidates []int
+	for i, s := range series {
+		if !s.checked {
+			continue
+		}
+		shown++
+		if _, ok := added[s.name]; ok {
+			candidates = append(candidates, i)
+		}
+	}
+	if shown <= max {
+		return
+	}
+
+	// Values that can't be plotted rank lowest
+	value := func(i int) float64 {
+		v := added[series[i].name]
+		if !isFinite(v) {
+			return math.Inf(-1)
+		}
+		return v
+	}
+	sort.SliceStable(candidates, func(a, b int) bool { return value(candidates[a]) < value(candidates[b]) })
+	for _, i := range candidates[:min(shown-max, len(candidates))] {
+		series[i].checked = false
+	}
+}
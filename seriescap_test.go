@@ -0,0 +1,64 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	zone "github.com/lrstanley/bubblezone"
+)
+
+func TestCapNewSeries(t *testing.T) {
+	series := []seriesItem{
+		{name: "a", checked: true},
+		{name: "b", checked: true},
+		{name: "c", checked: true},
+		{name: "d", checked: true},
+	}
+	capNewSeries(series, map[string]float64{"a": 5, "b": 1, "c": math.NaN(), "d": 9}, 2)
+
+	for i, want := range []bool{true, false, false, true} {
+		if series[i].checked != want {
+			t.Fatalf("expected the top 2 series to stay shown, got %+v", series)
+		}
+	}
+}
+
+func TestCapNewSeriesKeepsShownSeries(t *testing.T) {
+	// The user showed a and b before c was discovered
+	series := []seriesItem{
+		{name: "a", checked: true},
+		{name: "b", checked: true},
+		{name: "c", checked: true},
+	}
+	capNewSeries(series, map[string]float64{"c": 100}, 2)
+
+	if !series[0].checked || !series[1].checked || series[2].checked {
+		t.Fatalf("expected only the new series to be hidden, got %+v", series)
+	}
+}
+
+func TestMaxSeriesAppliesOnlyOnDiscovery(t *testing.T) {
+	zone.NewGlobal()
+	m := NewModel("http://localhost", "up", time.Second)
+	m.maxSeries = 1
+	now := time.Now()
+	samples := []MetricSample{
+		{FullName: `up{instance="a"}`, Value: 1},
+		{FullName: `up{instance="b"}`, Value: 2},
+	}
+
+	model, _ := m.Update(MetricsMsg{Samples: samples, Time: now})
+	m = model.(Model)
+	if m.seriesList[0].checked || !m.seriesList[1].checked {
+		t.Fatalf("expected only the top series to be shown, got %+v", m.seriesList)
+	}
+
+	// Showing the hidden series by hand sticks on the next scrape
+	m.setSeriesChecked(0, true)
+	model, _ = m.Update(MetricsMsg{Samples: samples, Time: now.Add(time.Second)})
+	m = model.(Model)
+	if !m.seriesList[0].checked || !m.seriesList[1].checked {
+		t.Fatalf("expected the manual toggle to stay, got %+v", m.seriesList)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// matchesSeriesFilter reports whether a series matches the filter text, either in
+// its raw name or in its key=value label pairs. Matching ignores case.
+func matchesSeriesFilter(name, filter string) bool {
+	if filter == "" {
+		return true
+	}
+	filter = strings.ToLower(filter)
+	return strings.Contains(strings.ToLower(name), filter) ||
+		strings.Contains(strings.ToLower(labelPairs(name)), filter)
+}
+
+// filteredSeries returns the positions in seriesList that match the series filter
+func (m *Model) filteredSeries() []int {
+	var indices []int
+	for i, s := range m.seriesList {
+		if matchesSeriesFilter(s.name, m.seriesFilter) {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// selectedSeries returns the position in seriesList of the selected item
+func (m *Model) selectedSeries() (int, bool) {
+	indices := m.filteredSeries()
+	if m.seriesListSelected < 0 || m.seriesListSelected >= len(indices) {
+		return 0, false
+	}
+	return indices[m.seriesListSelected], true
+}
+
+// updateSeriesFilter handles key presses while typing the series filter
+func (m *Model) updateSeriesFilter(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		// Drop the filter and show the full list again
+		m.seriesFilter = ""
+		m.seriesFiltering = false
+	case tea.KeyEnter:
+		m.seriesFiltering = false
+	case tea.KeyBackspace:
+		if runes := []rune(m.seriesFilter); len(runes) > 0 {
+			m.seriesFilter = string(runes[:len(runes)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.seriesFilter += string(msg.Runes)
+	default:
+		return
+	}
+	m.seriesListSelected = 0
+	m.seriesListScroll = 0
+}
+
+// seriesCountText tells how many series the metric has and how many of them
+// are shown, e.g. "12 series (8 shown)"
+func (m *Model) seriesCountText() string {
+	shown := 0
+	for _, s := range m.seriesList {
+		if s.checked {
+			shown++
+		}
+	}
+	return fmt.Sprintf("%d series (%d shown)", len(m.seriesList), shown)
+}
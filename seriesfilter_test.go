@@ -0,0 +1,77 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestMatchesSeriesFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		want   bool
+	}{
+		{`http_requests{method="GET",code="200"}`, "", true},
+		{`http_requests{method="GET",code="200"}`, "get", true},
+		{`http_requests{method="GET",code="200"}`, "method=GET", true},
+		{`http_requests{method="GET",code="200"}`, `code="200"`, true},
+		{`http_requests{method="GET",code="200"}`, "500", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchesSeriesFilter(tt.name, tt.filter); got != tt.want {
+			t.Fatalf("matchesSeriesFilter(%s, %s): expected %v, got %v", tt.name, tt.filter, tt.want, got)
+		}
+	}
+}
+
+func TestSeriesFilterKeepsCheckedState(t *testing.T) {
+	m := Model{
+		seriesSelectMode: true,
+		seriesList: []seriesItem{
+			{name: `up{instance="a"}`, checked: true},
+			{name: `up{instance="b"}`, checked: true},
+			{name: `up{instance="c"}`, checked: true},
+		},
+	}
+
+	press := func(msg tea.KeyMsg) {
+		model, _ := m.Update(msg)
+		m = model.(Model)
+	}
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("=b")})
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if got := m.filteredSeries(); len(got) != 1 || got[0] != 1 {
+		t.Fatalf("expected only the second series to match, got %v", got)
+	}
+
+	// Space toggles the selected item of the filtered list
+	press(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	if m.seriesList[1].checked || !m.seriesList[0].checked {
+		t.Fatalf("expected only the filtered series to be unchecked, got %v", m.seriesList)
+	}
+
+	// Clearing the filter restores the full list
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	if got := m.filteredSeries(); len(got) != 3 {
+		t.Fatalf("expected all series after clearing the filter, got %v", got)
+	}
+	if m.seriesList[1].checked {
+		t.Fatal("expected checked state to survive clearing the filter")
+	}
+}
+
+func TestSeriesCountText(t *testing.T) {
+	m := Model{seriesList: []seriesItem{
+		{name: `up{instance="a"}`, checked: true},
+		{name: `up{instance="b"}`},
+		{name: `up{instance="c"}`, checked: true},
+	}}
+	if got, want := m.seriesCountText(), "3 series (2 shown)"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Orders of the series list in series selection mode
+const (
+	seriesSortName  = "name"
+	seriesSortValue = "value"
+)
+
+// sortSeriesList sorts the series list by name or latest value, reversing the
+// order when sorted by the same key again. Series without a value or a NaN one
+// go last either way.
+// Colors stick to their series and the selection stays on the same series.
+func (m *Model) sortSeriesList(by string) {
+	if m.seriesSort == by {
+		m.seriesSortDesc = !m.seriesSortDesc
+	} else {
+		// Like the legend, values are sorted highest first
+		m.seriesSort, m.seriesSortDesc = by, by == seriesSortValue
+	}
+
+	selected := ""
+	if i, ok := m.selectedSeries(); ok {
+		selected = m.seriesList[i].name
+	}
+
+	sort.SliceStable(m.seriesList, func(i, j int) bool {
+		a, b := m.seriesList[i].name, m.seriesList[j].name
+		if by == seriesSortValue {
+			va, okA := m.currentValue(a)
+			vb, okB := m.currentValue(b)
+			okA, okB = okA && !math.IsNaN(va), okB && !math.IsNaN(vb)
+			if !okA || !okB {
+				return okA && !okB
+			}
+			if va != vb {
+				return (va < vb) != m.seriesSortDesc
+			}
+		}
+		return (a < b) != (m.seriesSortDesc && by == seriesSortName)
+	})
+	// The hovered legend entry is a position in the list, which moved
+	m.hoveredSeries = -1
+	m.rebuildLegend()
+
+	for pos, i := range m.filteredSeries() {
+		if m.seriesList[i].name == selected {
+			m.seriesListSelected = pos
+		}
+	}
+	maxVisible := max(m.termHeight-12, 3)
+	if m.seriesListSelected < m.seriesListScroll || m.seriesListSelected >= m.seriesListScroll+maxVisible {
+		m.seriesListScroll = max(m.seriesListSelected-maxVisible/2, 0)
+	}
+
+	order := "ascending"
+	if m.seriesSortDesc {
+		order = "descending"
+	}
+	m.status = fmt.Sprintf("Sorted by %s (%s)", by, order)
+}
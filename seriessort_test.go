@@ -0,0 +1,73 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	zone "github.com/lrstanley/bubblezone"
+)
+
+func TestSortSeriesList(t *testing.T) {
+	zone.NewGlobal()
+	m := NewModel("http://localhost", "up", time.Second)
+	model, _ := m.Update(MetricsMsg{
+		Samples: []MetricSample{
+			{FullName: `up{job="b"}`, Value: 3},
+			{FullName: `up{job="d"}`, Value: math.NaN()},
+			{FullName: `up{job="c"}`, Value: 2},
+			{FullName: `up{job="a"}`, Value: 1},
+		},
+		Time: time.Unix(1000, 0),
+	})
+	// Select job="c" in the series list
+	for _, k := range []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune("s")}, {Type: tea.KeyDown}, {Type: tea.KeyDown}} {
+		model, _ = model.Update(k)
+	}
+	m = model.(Model)
+
+	press := func(key string) {
+		model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		m = model.(Model)
+	}
+	order := func() []string {
+		var names []string
+		for _, s := range m.seriesList {
+			names = append(names, s.name)
+		}
+		return names
+	}
+	expectOrder := func(want []string, msg string) {
+		t.Helper()
+		for i, name := range order() {
+			if name != want[i] {
+				t.Fatalf("expected %s, got %v", msg, order())
+			}
+		}
+	}
+
+	press("v")
+	expectOrder([]string{`up{job="b"}`, `up{job="c"}`, `up{job="a"}`, `up{job="d"}`}, "highest values first and NaN last")
+	for _, s := range m.seriesList {
+		if s.name == `up{job="a"}` && s.colorIdx != 3 {
+			t.Fatalf("expected colors to stay with their series, got %d", s.colorIdx)
+		}
+	}
+
+	press("v")
+	expectOrder([]string{`up{job="a"}`, `up{job="c"}`, `up{job="b"}`, `up{job="d"}`}, "lowest values first and NaN still last")
+
+	press("n")
+	if got := order(); got[0] != `up{job="a"}` || got[3] != `up{job="d"}` {
+		t.Fatalf("expected ascending names, got %v", got)
+	}
+	if i, _ := m.selectedSeries(); m.seriesList[i].name != `up{job="c"}` {
+		t.Fatalf("expected the selection to follow its series, got %q", m.seriesList[i].name)
+	}
+
+	press("n")
+	if got := order(); got[0] != `up{job="d"}` {
+		t.Fatalf("expected descending names on the second sort, got %v", got)
+	}
+}
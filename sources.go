@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+)
+
+// sourceLabel is the label that tells apart the series of different URLs
+const sourceLabel = "source"
+
+// sourceHost returns the host of a URL to identify where a series was scraped
+func sourceHost(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+	return u.Host
+}
+
+// sourceNames returns the source label of every URL. That's the host, or the
+// host and path for URLs on a host shared with another URL.
+func sourceNames(urls []string) []string {
+	hosts := make(map[string]int)
+	for _, u := range urls {
+		hosts[sourceHost(u)]++
+	}
+	names := make([]string, len(urls))
+	for i, raw := range urls {
+		names[i] = sourceHost(raw)
+		if u, err := url.Parse(raw); err == nil && u.Host != "" && hosts[names[i]] > 1 {
+			names[i] = u.Host + u.Path
+		}
+	}
+	return names
+}
+
+// fetchSources fetches the series of a metric from all URLs concurrently. With more
+// than one URL every series is labeled with its source. Series of URLs that
+// failed are left out, the error is only returned alongside the others.
+func fetchSources(cfg fetchConfig, urls []string, metricName string, match metricMatch) ([]MetricSample, error) {
+	if len(urls) == 1 {
+		return fetchAllMetricSeries(cfg, urls[0], metricName, match)
+	}
+
+	sources := sourceNames(urls)
+	results := make([][]MetricSample, len(urls))
+	errs := make([]error, len(urls))
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		// Only the first URL reports its connection details
+		sourceCfg := cfg
+		if i > 0 {
+			sourceCfg.diagnostics = nil
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = fetchAllMetricSeries(sourceCfg, u, metricName, match)
+			if errs[i] != nil {
+				errs[i] = fmt.Errorf("%s: %w", sources[i], errs[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	var samples []MetricSample
+	for i, result := range results {
+		for _, sample := range result {
+			sample.FullName = withLabel(sample.FullName, sourceLabel, sources[i])
+			_, sample.Labels = parseLabels(sample.FullName)
+			samples = append(samples, sample)
+		}
+	}
+	return samples, errors.Join(errs...)
+}
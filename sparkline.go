@@ -0,0 +1,59 @@
+package main
+
+import (
+	"math"
+	"strings"
+
+	"github.com/NimbleMarkets/ntcharts/linechart/timeserieslinechart"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// sparkRunes are the block runes of a sparkline from lowest to highest
+var sparkRunes = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders the last n points as block runes scaled between their
+// minimum and maximum, or an empty string if there are no points
+func sparkline(points []timeserieslinechart.TimePoint, n int) string {
+	if len(points) == 0 || n <= 0 {
+		return ""
+	}
+	if len(points) > n {
+		points = points[len(points)-n:]
+	}
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, p := range points {
+		lo = math.Min(lo, p.Value)
+		hi = math.Max(hi, p.Value)
+	}
+
+	var sb strings.Builder
+	for _, p := range points {
+		// Flat lines sit in the middle rather than at the bottom
+		level := len(sparkRunes) / 2
+		if hi > lo {
+			level = int((p.Value - lo) / (hi - lo) * float64(len(sparkRunes)-1))
+		}
+		if math.IsNaN(p.Value) {
+			level = 0
+		}
+		sb.WriteRune(sparkRunes[level])
+	}
+	// Pad short histories so the labels next to them stay aligned
+	sb.WriteString(strings.Repeat(" ", n-len(points)))
+	return sb.String()
+}
+
+// legendSparklines returns the colored sparkline of every plotted line by name,
+// or nil if sparklines are disabled
+func (m *Model) legendSparklines() map[string]string {
+	if m.sparkPoints <= 0 {
+		return nil
+	}
+	sparks := make(map[string]string)
+	for _, line := range m.plotLines() {
+		color := m.seriesColors[line.colorIdx%len(m.seriesColors)]
+		sparks[line.name] = lipgloss.NewStyle().Foreground(color).Render(sparkline(line.points, m.sparkPoints))
+	}
+	return sparks
+}
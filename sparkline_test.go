@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		n      int
+		want   string
+	}{
+		{"rising", []float64{0, 1, 2, 3, 4, 5, 6, 7}, 8, "▁▂▃▄▅▆▇█"},
+		{"last points only", []float64{100, 0, 7}, 2, "▁█"},
+		{"flat", []float64{3, 3, 3}, 3, "▅▅▅"},
+		{"padded", []float64{1, 2}, 4, "▁█  "},
+		{"empty", nil, 4, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sparkline(trendPoints(tt.values...), tt.n); got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNarrowLegendWithSparklinesAndTrends(t *testing.T) {
+	m := NewModel("http://localhost", "up", time.Second)
+	m.legendWidth = minLegendWidth
+	m.trendWindow = time.Minute
+	m.sparkPoints = legendLabelMax(m.legendWidth, 0, true) - minLegendLabel - 1
+	m.legendViewport.Width, m.legendViewport.Height = 40, 20
+	name := `up{instance="a-rather-long-instance-name"}`
+	m.seriesList = []seriesItem{{name: name, checked: true}}
+	m.dataHistory[name] = trendPoints(1, 2, 3, 4)
+
+	m.rebuildLegend()
+	if legend := m.legendViewport.View(); !strings.Contains(legend, "...") {
+		t.Fatalf("expected a truncated label, got\n%s", legend)
+	}
+	if got := legendLabelMax(m.legendWidth, m.sparkPoints, true); got != minLegendLabel {
+		t.Fatalf("expected the narrowest label, got %d", got)
+	}
+	if got := legendLabelMax(m.legendWidth, 10, true); got != minLegendLabel {
+		t.Fatalf("expected labels to be at least %d wide, got %d", minLegendLabel, got)
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// newFetchSpinner returns the spinner shown in the header while scraping
+func newFetchSpinner() spinner.Model {
+	return spinner.New(
+		spinner.WithSpinner(spinner.MiniDot),
+		spinner.WithStyle(lipgloss.NewStyle().Foreground(accentColor)),
+	)
+}
+
+// startFetch marks a scrape as in flight and spins the spinner until its
+// MetricsMsg arrives
+func (m *Model) startFetch(cmd tea.Cmd) tea.Cmd {
+	m.fetching = true
+	return tea.Batch(cmd, m.spinner.Tick)
+}
+
+// fetchIndicator renders the spinner while a scrape is in flight, or a blank
+// of the same width so the title doesn't shift
+func (m *Model) fetchIndicator() string {
+	if !m.fetching {
+		return " "
+	}
+	return m.spinner.View()
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	zone "github.com/lrstanley/bubblezone"
+)
+
+func TestFetchSpinner(t *testing.T) {
+	zone.NewGlobal()
+	m := NewModel("http://localhost", "up", time.Second)
+	if !m.fetching {
+		t.Fatal("expected the first scrape to be in flight")
+	}
+	if _, cmd := m.Update(m.spinner.Tick()); cmd == nil {
+		t.Fatal("expected the spinner to keep spinning while scraping")
+	}
+
+	model, _ := m.Update(MetricsMsg{Samples: []MetricSample{{FullName: "up", Value: 1}}, Time: time.Now()})
+	m = model.(Model)
+	if m.fetching || m.fetchIndicator() != " " {
+		t.Fatal("expected the spinner to stop once the scrape arrived")
+	}
+	if _, cmd := m.Update(m.spinner.Tick()); cmd != nil {
+		t.Fatal("expected the spinner tick to be dropped")
+	}
+
+	model, cmd := m.Update(TickMsg{Gen: m.tickGen})
+	if !model.(Model).fetching || cmd == nil {
+		t.Fatal("expected the next tick to start a scrape")
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// appState is what is remembered between runs
+type appState struct {
+	Metric string `json:"metric"` // Last selected metric
+}
+
+// defaultStatePath returns the location of the state file in the user's config directory
+func defaultStatePath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "slashmetrics", "state.json")
+}
+
+// loadState reads the state file. A missing file is an empty state.
+func loadState(path string) (appState, error) {
+	var state appState
+	if path == "" {
+		return state, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, fmt.Errorf("failed to read state file: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("failed to parse state file %s: %w", path, err)
+	}
+	return state, nil
+}
+
+// saveState writes the state file, creating its directory if needed
+func saveState(path string, state appState) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
+
+// rememberedMetric returns the metric selected in the last run if the endpoint
+// still exposes it, or all of the overlaid ones, or an empty string otherwise
+func rememberedMetric(cfg fetchConfig, url, path string) string {
+	state, err := loadState(path)
+	if err != nil || state.Metric == "" {
+		return ""
+	}
+	metrics, err := fetchAllMetrics(cfg, url)
+	if err != nil {
+		return ""
+	}
+	for _, name := range metricNames(state.Metric) {
+		if !slices.Contains(metrics, name) {
+			return ""
+		}
+	}
+	return state.Metric
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestStateRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slashmetrics", "state.json")
+
+	state, err := loadState(path)
+	if err != nil || state.Metric != "" {
+		t.Fatalf("expected an empty state without a file, got %+v (%v)", state, err)
+	}
+
+	if err := saveState(path, appState{Metric: "up"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	state, err = loadState(path)
+	if err != nil || state.Metric != "up" {
+		t.Fatalf("expected the saved metric, got %+v (%v)", state, err)
+	}
+}
+
+func TestRememberedMetric(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("up 1\nbuild_info 1\n"))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	if got := rememberedMetric(fetchConfig{}, server.URL, path); got != "" {
+		t.Fatalf("expected no metric without state, got %q", got)
+	}
+
+	if err := saveState(path, appState{Metric: "up"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := rememberedMetric(fetchConfig{}, server.URL, path); got != "up" {
+		t.Fatalf("expected the remembered metric, got %q", got)
+	}
+
+	// Overlaid metrics are remembered as long as the endpoint exposes all of them
+	if err := saveState(path, appState{Metric: "up,build_info"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := rememberedMetric(fetchConfig{}, server.URL, path); got != "up,build_info" {
+		t.Fatalf("expected the remembered metrics, got %q", got)
+	}
+
+	// A metric the endpoint no longer exposes is forgotten
+	for _, metric := range []string{"gone", "up,gone"} {
+		if err := saveState(path, appState{Metric: metric}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := rememberedMetric(fetchConfig{}, server.URL, path); got != "" {
+			t.Fatalf("expected no metric once %s is gone, got %q", metric, got)
+		}
+	}
+}
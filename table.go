@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// tableRow is a checked series with its current value
+type tableRow struct {
+	name     string
+	colorIdx int
+	value    float64
+}
+
+// tableRows returns the checked series that have a value, largest value first
+func (m *Model) tableRows() []tableRow {
+	var rows []tableRow
+	for _, series := range m.seriesList {
+		if !series.checked {
+			continue
+		}
+		value, ok := m.currentValue(series.name)
+		if !ok {
+			continue
+		}
+		rows = append(rows, tableRow{name: series.name, colorIdx: series.colorIdx, value: value})
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].value > rows[j].value })
+	return rows
+}
+
+// tableView renders the current value of every checked series in place of the chart
+func (m *Model) tableView() string {
+	rows := m.tableRows()
+
+	values := make([]string, len(rows))
+	valueWidth := len("Value")
+	for i, row := range rows {
+		values[i] = strconv.FormatFloat(row.value, 'g', -1, 64)
+		valueWidth = max(valueWidth, len(values[i]))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render(fmt.Sprintf("  %*s  %s", valueWidth, "Value", "Series")))
+	sb.WriteString("\n")
+	for i, row := range rows {
+		color := m.seriesColors[row.colorIdx%len(m.seriesColors)]
+		indicator := seriesIndicator(color, row.colorIdx)
+		sb.WriteString(fmt.Sprintf("%s %*s  %s\n", indicator, valueWidth, values[i], row.name))
+	}
+	if len(rows) == 0 {
+		sb.WriteString(labelStyle.Render("No values of visible series yet"))
+	}
+
+	return lipgloss.NewStyle().
+		Width(m.width).
+		Height(m.height).
+		MaxWidth(m.width).
+		MaxHeight(m.height).
+		Render(sb.String())
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	zone "github.com/lrstanley/bubblezone"
+)
+
+func TestTableRows(t *testing.T) {
+	zone.NewGlobal()
+	m := NewModel("http://localhost", "up", time.Second)
+	model, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	model, _ = model.Update(MetricsMsg{
+		Samples: []MetricSample{
+			{FullName: `up{instance="a"}`, Value: 1},
+			{FullName: `up{instance="b"}`, Value: 3},
+			{FullName: `up{instance="c"}`, Value: 5},
+		},
+		Time: time.Unix(1000, 0),
+	})
+	m = model.(Model)
+	m.setSeriesChecked(2, false)
+
+	rows := m.tableRows()
+	if len(rows) != 2 || rows[0].name != `up{instance="b"}` || rows[1].name != `up{instance="a"}` {
+		t.Fatalf("expected checked series with values sorted descending, got %v", rows)
+	}
+	if rows[0].colorIdx != 1 {
+		t.Fatalf("expected the series color to be kept, got %d", rows[0].colorIdx)
+	}
+
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	view := model.(Model).View()
+	first, second := strings.Index(view, `3  up{instance="b"}`), strings.Index(view, `1  up{instance="a"}`)
+	if first == -1 || second == -1 || first > second || strings.Contains(view, `up{instance="c"}`) {
+		t.Fatalf("unexpected table view:\n%s", view)
+	}
+}
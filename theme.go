@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// theme holds the colors of the UI
+type theme struct {
+	accent     lipgloss.Color   // Title, borders, selections and highlights
+	background lipgloss.Color   // Background of the title and the help bar
+	series     []lipgloss.Color // Colors of the series lines, in order
+}
+
+// defaultTheme is used unless --theme picks another one
+const defaultTheme = "orange"
+
+// themes are the color themes selectable with --theme
+var themes = map[string]theme{
+	"orange": {
+		accent:     "#ff5f00",
+		background: "#282A35",
+		series: []lipgloss.Color{
+			"#ff5f00", "46", "226", "201", "51", "208", "99", "171",
+			"196", "33", "214", "40", "129", "39", "160", "45",
+			"220", "135", "118", "200", "81", "227", "161", "48",
+			"57", "190", "213", "38", "154", "124", "27", "141",
+		},
+	},
+	"blue": {
+		accent:     "#0087ff",
+		background: "#1c2333",
+		series: []lipgloss.Color{
+			"#0087ff", "46", "226", "201", "51", "208", "99", "171",
+			"196", "#ff5f00", "214", "40", "129", "39", "160", "45",
+			"220", "135", "118", "200", "81", "227", "161", "48",
+			"57", "190", "213", "38", "154", "124", "27", "141",
+		},
+	},
+	"mono": {
+		accent:     "15",
+		background: "235",
+		series:     []lipgloss.Color{"15", "250", "245", "240", "254", "247", "242", "252"},
+	},
+	"solarized": {
+		accent:     "#b58900",
+		background: "#002b36",
+		series: []lipgloss.Color{
+			"#b58900", "#268bd2", "#859900", "#d33682",
+			"#2aa198", "#cb4b16", "#6c71c4", "#dc322f",
+		},
+	},
+}
+
+// seriesPalette are the colors new models assign to their series
+var seriesPalette = themes[defaultTheme].series
+
+// themeNames returns the names of all themes in order
+func themeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyTheme recolors the package-level styles and the series palette
+func applyTheme(t theme) {
+	accentColor = t.accent
+	backgroundColor = t.background
+	defaultStyle = defaultStyle.Background(t.background)
+	titleStyle = titleStyle.Foreground(t.accent).Background(t.background)
+	borderStyle = borderStyle.BorderForeground(t.accent)
+	graphStyle = graphStyle.Foreground(t.accent)
+	listSelectedItemStyle = listSelectedItemStyle.Foreground(t.accent)
+	listTitleStyle = listTitleStyle.Foreground(t.accent)
+	pausedStyle = pausedStyle.Background(t.accent)
+	brushStyle = brushStyle.Foreground(t.accent)
+	seriesPalette = t.series
+}
+
+// extendPalette adds generated colors to a palette until it has n of them, so
+// series beyond the palette don't repeat the colors of the first ones. Hues are
+// spread by the golden angle, alternating brightness between neighbours.
+func extendPalette(palette []lipgloss.Color, n int) []lipgloss.Color {
+	palette = slices.Clip(palette)
+	for i := len(palette); i < n; i++ {
+		hue := math.Mod(float64(i)*137.508, 360)
+		value := 1.0
+		if i%2 == 1 {
+			value = 0.75
+		}
+		palette = append(palette, hsvColor(hue, 0.8, value))
+	}
+	return palette
+}
+
+// hsvColor converts a hue (in degrees), saturation and value to an RGB color
+func hsvColor(h, s, v float64) lipgloss.Color {
+	c := v * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g = c, x
+	case h < 120:
+		r, g = x, c
+	case h < 180:
+		g, b = c, x
+	case h < 240:
+		g, b = x, c
+	case h < 300:
+		r, b = x, c
+	default:
+		r, b = c, x
+	}
+	m := v - c
+	return lipgloss.Color(fmt.Sprintf("#%02x%02x%02x",
+		int(math.Round((r+m)*255)), int(math.Round((g+m)*255)), int(math.Round((b+m)*255))))
+}
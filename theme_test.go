@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestApplyTheme(t *testing.T) {
+	defer applyTheme(themes[defaultTheme])
+
+	applyTheme(themes["solarized"])
+	if accentColor != "#b58900" || titleStyle.GetForeground() != lipgloss.Color("#b58900") {
+		t.Fatalf("expected the solarized accent, got %v", titleStyle.GetForeground())
+	}
+	if m := NewModel("http://localhost", "up", time.Second); m.seriesColors[0] != "#b58900" {
+		t.Fatalf("expected the solarized palette, got %v", m.seriesColors)
+	}
+	if !titleStyle.GetBold() {
+		t.Fatal("expected the other properties of the styles to stay")
+	}
+}
+
+func TestThemesHaveSeriesColors(t *testing.T) {
+	for _, name := range themeNames() {
+		if len(themes[name].series) == 0 {
+			t.Errorf("theme %q has no series colors", name)
+		}
+	}
+}
+
+func TestExtendPalette(t *testing.T) {
+	palette := themes["solarized"].series
+	extended := extendPalette(palette, 40)
+	if len(extended) != 40 {
+		t.Fatalf("expected 40 colors, got %d", len(extended))
+	}
+	seen := make(map[lipgloss.Color]bool)
+	for i, color := range extended {
+		if i < len(palette) && color != palette[i] {
+			t.Fatalf("expected the palette to stay first, got %v at %d", color, i)
+		}
+		if seen[color] {
+			t.Fatalf("expected distinct colors, got %v twice", color)
+		}
+		seen[color] = true
+	}
+	if got := extendPalette(palette, 3); len(got) != len(palette) {
+		t.Fatalf("expected a large enough palette to stay as is, got %d colors", len(got))
+	}
+}
+
+func TestHSVColor(t *testing.T) {
+	tests := []struct {
+		h, s, v float64
+		want    lipgloss.Color
+	}{
+		{0, 1, 1, "#ff0000"},
+		{120, 1, 1, "#00ff00"},
+		{240, 1, 1, "#0000ff"},
+		{0, 0, 0.5, "#808080"},
+	}
+	for _, tt := range tests {
+		if got := hsvColor(tt.h, tt.s, tt.v); got != tt.want {
+			t.Errorf("hsvColor(%v, %v, %v) = %v, want %v", tt.h, tt.s, tt.v, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/NimbleMarkets/ntcharts/canvas"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// thresholdOp is how values are compared against the --threshold
+type thresholdOp string
+
+const (
+	thresholdAbove thresholdOp = "gt" // Values greater than the threshold exceed it
+	thresholdBelow thresholdOp = "lt" // Values less than the threshold exceed it
+)
+
+// validate checks that the comparison is one of the known ones
+func (op thresholdOp) validate() error {
+	switch op {
+	case thresholdAbove, thresholdBelow:
+		return nil
+	default:
+		return fmt.Errorf("invalid --threshold-op value %q (expected gt or lt)", string(op))
+	}
+}
+
+// exceeds reports whether a value is beyond the threshold
+func (op thresholdOp) exceeds(value, threshold float64) bool {
+	if op == thresholdBelow {
+		return value < threshold
+	}
+	return value > threshold
+}
+
+// thresholdColor marks the parts of lines beyond the threshold
+var thresholdColor = lipgloss.Color("196")
+
+// thresholdLegendStyle flashes the legend entries of series currently beyond the threshold
+var thresholdLegendStyle = lipgloss.NewStyle().Foreground(thresholdColor).Bold(true).Blink(true)
+
+// thresholdRow returns the canvas row of the threshold on the chart
+func (m *Model) thresholdRow() (int, bool) {
+	if m.threshold == nil {
+		return 0, false
+	}
+	v, ok := m.chartValue(*m.threshold)
+	dy := m.chart.ViewMaxY() - m.chart.ViewMinY()
+	if !ok || dy <= 0 {
+		return 0, false
+	}
+	// Lines are scaled to the full graph height, see ScaleFloat64PointForLine
+	scaled := (v - m.chart.ViewMinY()) / dy * float64(m.chart.GraphHeight())
+	return m.chart.Origin().Y - int(math.Round(scaled)), true
+}
+
+// markThreshold recolors the drawn lines beyond the threshold. Everything
+// drawn on the far side of the threshold's row belongs to a line exceeding it,
+// whichever series it is.
+func (m *Model) markThreshold() {
+	row, ok := m.thresholdRow()
+	if !ok {
+		return
+	}
+
+	// Canvas rows count from the top, so greater values are in the rows above
+	beyond := func(y int) bool {
+		if m.thresholdOp == thresholdBelow {
+			return y > row
+		}
+		return y < row
+	}
+
+	origin := m.chart.Origin()
+	for y := 0; y < origin.Y; y++ {
+		if !beyond(y) {
+			continue
+		}
+		for x := origin.X + 1; x < m.chart.Width(); x++ {
+			p := canvas.Point{X: x, Y: y}
+			cell := m.chart.Canvas.Cell(p)
+			if cell.Rune == 0 || cell.Rune == ' ' {
+				continue
+			}
+			cell.Style = cell.Style.Foreground(thresholdColor)
+			m.chart.Canvas.SetCell(p, cell)
+		}
+	}
+}
+
+// overThreshold returns the plotted lines whose latest value is beyond the threshold
+func (m *Model) overThreshold() map[string]bool {
+	if m.threshold == nil {
+		return nil
+	}
+	over := make(map[string]bool)
+	for _, line := range m.plotLines() {
+		if len(line.points) == 0 {
+			continue
+		}
+		if m.thresholdOp.exceeds(line.points[len(line.points)-1].Value, *m.threshold) {
+			over[line.name] = true
+		}
+	}
+	return over
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NimbleMarkets/ntcharts/canvas"
+	zone "github.com/lrstanley/bubblezone"
+)
+
+func TestThresholdOp(t *testing.T) {
+	if !thresholdAbove.exceeds(2, 1) || thresholdAbove.exceeds(1, 1) {
+		t.Error("expected gt to exceed only above the threshold")
+	}
+	if !thresholdBelow.exceeds(0, 1) || thresholdBelow.exceeds(1, 1) {
+		t.Error("expected lt to exceed only below the threshold")
+	}
+	if err := thresholdOp("ge").validate(); err == nil {
+		t.Error("expected an error for an unknown comparison")
+	}
+}
+
+func TestMarkThreshold(t *testing.T) {
+	zone.NewGlobal()
+	start := time.Unix(1700000000, 0)
+	threshold := 50.0
+	m := NewModel("http://localhost", "up", time.Second)
+	m.threshold = &threshold
+	m.thresholdOp = thresholdAbove
+	for i := range 10 {
+		model, _ := m.Update(MetricsMsg{
+			Samples: []MetricSample{{FullName: `up{instance="a"}`, Value: 30}, {FullName: `up{instance="b"}`, Value: 90}},
+			Time:    start.Add(time.Duration(i) * time.Second),
+		})
+		m = model.(Model)
+	}
+
+	row, ok := m.thresholdRow()
+	if !ok {
+		t.Fatal("expected the threshold on the chart")
+	}
+	marked := func(y int) (drawn, red bool) {
+		for x := m.chart.Origin().X + 1; x < m.chart.Width(); x++ {
+			cell := m.chart.Canvas.Cell(canvas.Point{X: x, Y: y})
+			if cell.Rune == 0 || cell.Rune == ' ' {
+				continue
+			}
+			drawn = true
+			red = red || cell.Style.GetForeground() == thresholdColor
+		}
+		return drawn, red
+	}
+
+	var above, below bool
+	for y := 0; y < m.chart.Origin().Y; y++ {
+		drawn, red := marked(y)
+		if !drawn {
+			continue
+		}
+		if y < row {
+			above = true
+			if !red {
+				t.Errorf("expected the line in row %d above the threshold to be red", y)
+			}
+		} else if y > row {
+			below = true
+			if red {
+				t.Errorf("expected the line in row %d below the threshold to keep its color", y)
+			}
+		}
+	}
+	if !above || !below {
+		t.Fatalf("expected lines on both sides of the threshold (above %v, below %v)", above, below)
+	}
+
+	if over := m.overThreshold(); !over[`up{instance="b"}`] || over[`up{instance="a"}`] {
+		t.Fatalf("expected only the upper series over the threshold, got %v", over)
+	}
+}
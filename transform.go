@@ -0,0 +1,53 @@
+package main
+
+import (
+	"github.com/NimbleMarkets/ntcharts/linechart/timeserieslinechart"
+)
+
+// cumulativeSum returns the running total of the given points
+func cumulativeSum(points []timeserieslinechart.TimePoint) []timeserieslinechart.TimePoint {
+	result := make([]timeserieslinechart.TimePoint, len(points))
+	total := 0.0
+	for i, point := range points {
+		total += point.Value
+		result[i] = timeserieslinechart.TimePoint{Time: point.Time, Value: total}
+	}
+	return result
+}
+
+// ratePerSecond returns the per-second increase between consecutive points of a
+// counter. A drop in value is a counter reset, after which the counter counts from zero.
+func ratePerSecond(points []timeserieslinechart.TimePoint) []timeserieslinechart.TimePoint {
+	var result []timeserieslinechart.TimePoint
+	for i := 1; i < len(points); i++ {
+		elapsed := points[i].Time.Sub(points[i-1].Time).Seconds()
+		if elapsed <= 0 {
+			continue
+		}
+		increase := points[i].Value - points[i-1].Value
+		if increase < 0 {
+			increase = points[i].Value
+		}
+		result = append(result, timeserieslinechart.TimePoint{Time: points[i].Time, Value: increase / elapsed})
+	}
+	return result
+}
+
+// relativeTo expresses points as a percentage of the reference value at the
+// same time, skipping times where the reference is missing or zero
+func relativeTo(points, reference []timeserieslinechart.TimePoint) []timeserieslinechart.TimePoint {
+	refValues := make(map[int64]float64, len(reference))
+	for _, point := range reference {
+		refValues[point.Time.UnixNano()] = point.Value
+	}
+
+	var result []timeserieslinechart.TimePoint
+	for _, point := range points {
+		ref, ok := refValues[point.Time.UnixNano()]
+		if !ok || ref == 0 {
+			continue
+		}
+		result = append(result, timeserieslinechart.TimePoint{Time: point.Time, Value: point.Value / ref * 100})
+	}
+	return result
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NimbleMarkets/ntcharts/linechart/timeserieslinechart"
+)
+
+func TestCumulativeSum(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	points := []timeserieslinechart.TimePoint{
+		{Time: start, Value: 1},
+		{Time: start.Add(time.Second), Value: 2.5},
+		{Time: start.Add(2 * time.Second), Value: -0.5},
+	}
+
+	got := cumulativeSum(points)
+	want := []timeserieslinechart.TimePoint{
+		{Time: start, Value: 1},
+		{Time: start.Add(time.Second), Value: 3.5},
+		{Time: start.Add(2 * time.Second), Value: 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if points[1].Value != 2.5 {
+		t.Fatalf("expected input to be left untouched")
+	}
+	if got := cumulativeSum(nil); len(got) != 0 {
+		t.Fatalf("expected empty result, got %v", got)
+	}
+}
+
+func TestRelativeTo(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	at := func(offset int, v float64) timeserieslinechart.TimePoint {
+		return timeserieslinechart.TimePoint{Time: start.Add(time.Duration(offset) * time.Second), Value: v}
+	}
+	reference := []timeserieslinechart.TimePoint{at(0, 200), at(1, 0), at(3, 50)}
+	points := []timeserieslinechart.TimePoint{at(0, 100), at(1, 5), at(2, 7), at(3, 75)}
+
+	got := relativeTo(points, reference)
+	want := []timeserieslinechart.TimePoint{at(0, 50), at(3, 150)}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestRatePerSecond(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	points := []timeserieslinechart.TimePoint{
+		{Time: start, Value: 10},
+		{Time: start.Add(2 * time.Second), Value: 20},
+		{Time: start.Add(4 * time.Second), Value: 20},
+		{Time: start.Add(6 * time.Second), Value: 4}, // counter reset
+	}
+
+	got := ratePerSecond(points)
+	want := []timeserieslinechart.TimePoint{
+		{Time: start.Add(2 * time.Second), Value: 5},
+		{Time: start.Add(4 * time.Second), Value: 0},
+		{Time: start.Add(6 * time.Second), Value: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if got := ratePerSecond(points[:1]); len(got) != 0 {
+		t.Fatalf("expected no rate for a single point, got %v", got)
+	}
+}
+
+func TestRateActive(t *testing.T) {
+	m := NewModel("http://localhost", "http_requests_total", time.Second)
+	m.rate = true
+	if m.rateActive() {
+		t.Fatal("expected no rate without counter metadata")
+	}
+	m.metadata = map[string]MetricMeta{"http_requests_total": {Type: "counter"}}
+	if !m.rateActive() || !m.transformed() {
+		t.Fatal("expected counters to be plotted as rate")
+	}
+
+	// OpenMetrics declares counters without the _total suffix of their samples
+	m.metadata = map[string]MetricMeta{"http_requests": {Type: "counter"}}
+	if !m.rateActive() {
+		t.Fatal("expected OpenMetrics counters to be plotted as rate")
+	}
+}
+
+func TestRateOfOverlaidMetrics(t *testing.T) {
+	m := NewModel("http://localhost", "requests_total,queue_length", time.Second)
+	m.rate = true
+	m.metadata = map[string]MetricMeta{"requests": {Type: "counter"}, "queue_length": {Type: "gauge"}}
+	if !m.rateActive() {
+		t.Fatal("expected rate for the counter of the overlaid metrics")
+	}
+
+	m.seriesList = []seriesItem{{name: `requests_total{code="200"}`, checked: true}, {name: `queue_length{}`, checked: true}}
+	m.dataHistory[`requests_total{code="200"}`] = trendPoints(1, 3, 5)
+	m.dataHistory[`queue_length{}`] = trendPoints(1, 3, 5)
+	for _, line := range m.plotLines() {
+		if want := map[string]int{`requests_total{code="200"}`: 2, `queue_length{}`: 3}[line.name]; len(line.points) != want {
+			t.Fatalf("expected %d points of %s, got %d", want, line.name, len(line.points))
+		}
+	}
+	m.termWidth = 120
+	if info := m.infoView(); !strings.Contains(info, "requests_total TYPE: counter") || !strings.Contains(info, "queue_length TYPE: gauge") {
+		t.Fatalf("expected the TYPE of every metric, got %q", info)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/NimbleMarkets/ntcharts/linechart/timeserieslinechart"
+)
+
+// trendSlope fits a line through the points and returns its slope per second
+func trendSlope(points []timeserieslinechart.TimePoint) (float64, bool) {
+	if len(points) < 2 {
+		return 0, false
+	}
+
+	n := float64(len(points))
+	var sumX, sumY, sumXY, sumXX float64
+	for _, p := range points {
+		x := p.Time.Sub(points[0].Time).Seconds()
+		sumX += x
+		sumY += p.Value
+		sumXY += x * p.Value
+		sumXX += x * x
+	}
+	denom := n*sumXX - sumX*sumX
+	if denom == 0 {
+		return 0, false
+	}
+	return (n*sumXY - sumX*sumY) / denom, true
+}
+
+// trendArrow returns an arrow for the direction of a linear fit through the
+// points of the last window, or an empty string if there are too few points
+func trendArrow(points []timeserieslinechart.TimePoint, window time.Duration) string {
+	if len(points) == 0 {
+		return ""
+	}
+
+	start := points[len(points)-1].Time.Add(-window)
+	first := len(points) - 1
+	for first > 0 && !points[first-1].Time.Before(start) {
+		first--
+	}
+	points = points[first:]
+
+	slope, ok := trendSlope(points)
+	if !ok {
+		return ""
+	}
+
+	// Ignore changes that are only floating point noise relative to the values
+	mean := 0.0
+	for _, p := range points {
+		mean += p.Value
+	}
+	mean /= float64(len(points))
+	change := slope * points[len(points)-1].Time.Sub(points[0].Time).Seconds()
+	switch {
+	case math.Abs(change) <= 1e-9*math.Max(1, math.Abs(mean)):
+		return "→"
+	case change > 0:
+		return "↑"
+	default:
+		return "↓"
+	}
+}
+
+// legendTrends returns the trend arrow of every plotted line by name, or nil if
+// trend arrows are disabled
+func (m *Model) legendTrends() map[string]string {
+	if m.trendWindow <= 0 {
+		return nil
+	}
+	trends := make(map[string]string)
+	for _, line := range m.plotLines() {
+		trends[line.name] = trendArrow(line.points, m.trendWindow)
+	}
+	return trends
+}
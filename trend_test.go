@@ -0,0 +1,51 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/NimbleMarkets/ntcharts/linechart/timeserieslinechart"
+)
+
+// trendPoints returns points one second apart with the given values
+func trendPoints(values ...float64) []timeserieslinechart.TimePoint {
+	start := time.Unix(1000, 0)
+	points := make([]timeserieslinechart.TimePoint, len(values))
+	for i, v := range values {
+		points[i] = timeserieslinechart.TimePoint{Time: start.Add(time.Duration(i) * time.Second), Value: v}
+	}
+	return points
+}
+
+func TestTrendSlope(t *testing.T) {
+	slope, ok := trendSlope(trendPoints(1, 3, 5, 7))
+	if !ok || math.Abs(slope-2) > 1e-9 {
+		t.Fatalf("expected slope 2, got %v (%v)", slope, ok)
+	}
+	if _, ok := trendSlope(trendPoints(1)); ok {
+		t.Fatal("expected no slope for a single point")
+	}
+}
+
+func TestTrendArrow(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []timeserieslinechart.TimePoint
+		window time.Duration
+		want   string
+	}{
+		{"rising with jitter", trendPoints(1, 3, 2, 4, 3, 5), time.Minute, "↑"},
+		{"falling", trendPoints(5, 4, 3), time.Minute, "↓"},
+		{"flat", trendPoints(2, 2, 2), time.Minute, "→"},
+		{"only the window counts", trendPoints(100, 1, 2, 3), 2 * time.Second, "↑"},
+		{"single point", trendPoints(1), time.Minute, ""},
+		{"no points", nil, time.Minute, ""},
+	}
+
+	for _, tt := range tests {
+		if got := trendArrow(tt.points, tt.window); got != tt.want {
+			t.Fatalf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
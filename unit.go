@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// valueUnit is how values are formatted on the Y axis
+type valueUnit string
+
+const (
+	unitNone     valueUnit = "none"     // Plain numbers
+	unitBytes    valueUnit = "bytes"    // Binary multiples, e.g. 1.5 GiB
+	unitDuration valueUnit = "duration" // Seconds, e.g. 250ms
+	unitPercent  valueUnit = "percent"  // Ratios of 0 to 1 as percentages
+	unitSI       valueUnit = "si"       // Decimal SI suffixes, e.g. 1.2k
+	unitAuto     valueUnit = "auto"     // Picked from the suffix of the metric name
+)
+
+// validate checks that the unit is one of the known ones
+func (u valueUnit) validate() error {
+	switch u {
+	case unitNone, unitBytes, unitDuration, unitPercent, unitSI, unitAuto:
+		return nil
+	default:
+		return fmt.Errorf("invalid --unit value %q (expected none, bytes, duration, percent, si or auto)", string(u))
+	}
+}
+
+// unitSuffixes are the metric name suffixes of the Prometheus naming conventions
+// that imply a unit
+var unitSuffixes = map[string]valueUnit{
+	"_bytes":   unitBytes,
+	"_seconds": unitDuration,
+	"_ratio":   unitPercent,
+}
+
+// metricUnit returns the unit implied by the name of a metric, ignoring the
+// suffixes of counters and histograms
+func metricUnit(metricName string) valueUnit {
+	for _, suffix := range []string{"_total", "_sum", "_bucket", "_count"} {
+		metricName = strings.TrimSuffix(metricName, suffix)
+	}
+	for suffix, unit := range unitSuffixes {
+		if strings.HasSuffix(metricName, suffix) {
+			return unit
+		}
+	}
+	return unitNone
+}
+
+// resolve picks the unit of an auto unit from the metric names, which have to
+// agree on it when several metrics share the chart
+func (u valueUnit) resolve(metricName string) valueUnit {
+	if u != unitAuto {
+		return u
+	}
+	names := metricNames(metricName)
+	if len(names) == 0 {
+		return unitNone
+	}
+	unit := metricUnit(names[0])
+	for _, name := range names[1:] {
+		if metricUnit(name) != unit {
+			return unitNone
+		}
+	}
+	return unit
+}
+
+// binaryPrefixes are the prefixes of formatBytes for each power of 1024
+var binaryPrefixes = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+
+// formatBytes formats a number of bytes with a binary prefix, e.g. "1.5 GiB"
+func formatBytes(v float64) string {
+	i := 0
+	for math.Abs(v) >= 1024 && i < len(binaryPrefixes)-1 {
+		v /= 1024
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%.0f %s", v, binaryPrefixes[i])
+	}
+	return fmt.Sprintf("%.3g %s", v, binaryPrefixes[i])
+}
+
+// durationUnits are the units of formatSeconds, largest first
+var durationUnits = []struct {
+	suffix  string
+	seconds float64
+}{
+	{"h", 3600},
+	{"m", 60},
+	{"s", 1},
+	{"ms", 1e-3},
+	{"µs", 1e-6},
+	{"ns", 1e-9},
+}
+
+// formatSeconds formats a number of seconds in the largest fitting unit, e.g. "250ms"
+func formatSeconds(v float64) string {
+	if v == 0 {
+		return "0s"
+	}
+	for _, u := range durationUnits {
+		if math.Abs(v) >= u.seconds {
+			return fmt.Sprintf("%.3g%s", v/u.seconds, u.suffix)
+		}
+	}
+	return fmt.Sprintf("%.3gns", v/1e-9)
+}
+
+// unitFormatter returns the Y label formatter of a unit, with the decimal
+// places of yLabelFormatter for plain numbers
+func unitFormatter(u valueUnit, precision int) func(int, float64) string {
+	switch u {
+	case unitBytes:
+		return func(_ int, v float64) string { return formatBytes(v) }
+	case unitDuration:
+		return func(_ int, v float64) string { return formatSeconds(v) }
+	case unitPercent:
+		return func(_ int, v float64) string { return fmt.Sprintf("%.3g%%", v*100) }
+	case unitSI:
+		return func(_ int, v float64) string { return compactValue(v) }
+	default:
+		return yLabelFormatter(precision)
+	}
+}
+
+// yFormatter returns the formatter of values of the current metric
+func (m *Model) yFormatter() func(int, float64) string {
+	return unitFormatter(m.unit.resolve(m.metricName), m.precision)
+}
@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func TestUnitFormatter(t *testing.T) {
+	tests := []struct {
+		unit valueUnit
+		v    float64
+		want string
+	}{
+		{unitBytes, 512, "512 B"},
+		{unitBytes, 1.5 * 1024 * 1024 * 1024, "1.5 GiB"},
+		{unitDuration, 0.25, "250ms"},
+		{unitDuration, 90, "1.5m"},
+		{unitDuration, 0, "0s"},
+		{unitPercent, 0.125, "12.5%"},
+		{unitSI, 1234, "1.23k"},
+		{unitNone, 12.5, "12.50"},
+	}
+	for _, tt := range tests {
+		if got := unitFormatter(tt.unit, adaptivePrecision)(0, tt.v); got != tt.want {
+			t.Fatalf("%s formatter of %v: expected %q, got %q", tt.unit, tt.v, tt.want, got)
+		}
+	}
+}
+
+func TestResolveUnit(t *testing.T) {
+	tests := []struct {
+		unit       valueUnit
+		metricName string
+		want       valueUnit
+	}{
+		{unitAuto, "node_memory_MemFree_bytes", unitBytes},
+		{unitAuto, "http_request_duration_seconds_bucket", unitDuration},
+		{unitAuto, "process_cpu_seconds_total", unitDuration},
+		{unitAuto, "cache_hit_ratio", unitPercent},
+		{unitAuto, "http_requests_total", unitNone},
+		{unitAuto, "a_bytes,b_bytes", unitBytes},
+		{unitAuto, "a_bytes,b_seconds", unitNone},
+		{unitSI, "a_bytes", unitSI},
+	}
+	for _, tt := range tests {
+		if got := tt.unit.resolve(tt.metricName); got != tt.want {
+			t.Fatalf("%s unit of %s: expected %s, got %s", tt.unit, tt.metricName, tt.want, got)
+		}
+	}
+}
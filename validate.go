@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	validateMetricFlag string
+	validateCmd        = &cobra.Command{
+		Use:   "validate <url>",
+		Short: "Check that an endpoint serves the expected metric and exit",
+		Args:  cobra.ExactArgs(1),
+		// The report already explains what went wrong
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := newFetchConfig()
+			if err != nil {
+				return err
+			}
+			return runValidate(cfg, args[0], validateMetricFlag, cmd.OutOrStdout())
+		},
+	}
+)
+
+func init() {
+	validateCmd.Flags().StringVar(&validateMetricFlag, "metric", "", "The metric that is expected to be exposed")
+	rootCmd.AddCommand(validateCmd)
+}
+
+// validationReport summarizes a single scrape of an endpoint
+type validationReport struct {
+	StatusCode    int
+	Samples       int // Lines that parsed as samples
+	InvalidLines  int // Lines that couldn't be parsed at all
+	Metrics       int // Number of distinct metric names
+	Series        int // Number of series of the requested metric
+	InvalidValues int // Series of the requested metric whose value didn't parse
+}
+
+// validateEndpoint scrapes the endpoint once and collects a validation report
+func validateEndpoint(cfg fetchConfig, url, metricName string) (validationReport, error) {
+	var report validationReport
+
+	resp, err := cfg.do(url)
+	if err != nil {
+		return report, fmt.Errorf("failed to fetch metrics: %w", err)
+	}
+	defer resp.Body.Close()
+
+	report.StatusCode = resp.StatusCode
+	if resp.StatusCode != http.StatusOK {
+		return report, nil
+	}
+	if err := responseError(resp); err != nil {
+		return report, err
+	}
+
+	metrics := make(map[string]bool)
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if isEOF(line) {
+			break
+		}
+
+		// Skip comments and empty lines
+		if strings.HasPrefix(line, "#") || len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		fullName, fields, ok := splitMetricLine(line)
+		if !ok {
+			report.InvalidLines++
+			continue
+		}
+
+		baseName := fullName
+		if idx := strings.Index(fullName, "{"); idx != -1 {
+			baseName = fullName[:idx]
+		}
+
+		_, err := strconv.ParseFloat(fields[0], 64)
+		if baseName == metricName {
+			report.Series++
+			if err != nil {
+				report.InvalidValues++
+			}
+		}
+		if err != nil {
+			report.InvalidLines++
+			continue
+		}
+
+		report.Samples++
+		metrics[baseName] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return report, fmt.Errorf("failed to read metrics: %w", err)
+	}
+
+	report.Metrics = len(metrics)
+	return report, nil
+}
+
+// printValidationReport writes a human readable report and returns whether all checks passed
+func printValidationReport(w io.Writer, url, metricName string, report validationReport) bool {
+	ok := true
+	check := func(passed bool, format string, args ...any) {
+		mark := "✓"
+		if !passed {
+			mark = "✗"
+			ok = false
+		}
+		fmt.Fprintf(w, "%s %s\n", mark, fmt.Sprintf(format, args...))
+	}
+
+	check(report.StatusCode == http.StatusOK, "endpoint %s reachable (HTTP %d)", redactURL(url), report.StatusCode)
+	if report.StatusCode != http.StatusOK {
+		return ok
+	}
+
+	check(report.Samples > 0 && report.InvalidLines == 0,
+		"valid exposition format (%d samples across %d metrics, %d invalid lines)",
+		report.Samples, report.Metrics, report.InvalidLines)
+
+	if metricName == "" {
+		return ok
+	}
+
+	check(report.Series > 0, "metric %q found (%d series)", metricName, report.Series)
+	if report.Series > 0 {
+		check(report.InvalidValues == 0, "values of %q parse (%d invalid)", metricName, report.InvalidValues)
+	}
+
+	return ok
+}
+
+// runValidate performs a pre-flight check of the endpoint without starting the TUI
+func runValidate(cfg fetchConfig, url, metricName string, w io.Writer) error {
+	report, err := validateEndpoint(cfg, url, metricName)
+	if err != nil {
+		fmt.Fprintf(w, "✗ endpoint %s reachable (%v)\n", redactURL(url), err)
+		return fmt.Errorf("validation failed")
+	}
+
+	if !printValidationReport(w, url, metricName, report) {
+		return fmt.Errorf("validation failed")
+	}
+	return nil
+}
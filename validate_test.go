@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidateEndpoint(t *testing.T) {
+	body := "" +
+		"# TYPE test_metric gauge\n" +
+		"test_metric{env=\"prod\"} 1.23\n" +
+		"test_metric{env=\"dev\"} oops\n" +
+		"other_metric 5\n" +
+		"garbage\n"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	report, err := validateEndpoint(fetchConfig{}, server.URL, "test_metric")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := validationReport{
+		StatusCode:    http.StatusOK,
+		Samples:       2,
+		InvalidLines:  2,
+		Metrics:       2,
+		Series:        2,
+		InvalidValues: 1,
+	}
+	if report != want {
+		t.Fatalf("expected %+v, got %+v", want, report)
+	}
+
+	var out bytes.Buffer
+	if printValidationReport(&out, server.URL, "test_metric", report) {
+		t.Fatalf("expected validation to fail")
+	}
+	if !strings.Contains(out.String(), `✓ metric "test_metric" found (2 series)`) {
+		t.Fatalf("expected series count in report, got:\n%s", out.String())
+	}
+}
+
+func TestValidateEndpointHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	if err := runValidate(fetchConfig{}, server.URL, "test_metric", &out); err == nil {
+		t.Fatalf("expected validation to fail on non-200 status")
+	}
+	if !strings.Contains(out.String(), "HTTP 404") {
+		t.Fatalf("expected status code in report, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/spf13/cobra"
+)
+
+// Build information, set at build time by goreleaser
+var (
+	version = "dev"
+	commit  = "none"
+	date    = "unknown"
+)
+
+// versionCmd prints the build information for bug reports
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version, commit and build date",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		fmt.Fprintln(cmd.OutOrStdout(), versionText())
+	},
+}
+
+// buildInfo returns version, commit and build date. Binaries built with go
+// install instead of goreleaser take them from the build info Go embeds.
+func buildInfo() (v, c, d string) {
+	v, c, d = version, commit, date
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return v, c, d
+	}
+	if v == "dev" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		v = info.Main.Version
+	}
+	for _, setting := range info.Settings {
+		switch {
+		case setting.Key == "vcs.revision" && c == "none":
+			c = setting.Value
+		case setting.Key == "vcs.time" && d == "unknown":
+			d = setting.Value
+		}
+	}
+	return v, c, d
+}
+
+// versionText describes the build in a single line
+func versionText() string {
+	v, c, d := buildInfo()
+	return fmt.Sprintf("slashmetrics %s (commit %s, built %s)", v, c, d)
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestVersionCmd(t *testing.T) {
+	defer func(v, c, d string) { version, commit, date = v, c, d }(version, commit, date)
+	version, commit, date = "1.2.3", "abc123", "2024-01-02T03:04:05Z"
+
+	var out bytes.Buffer
+	versionCmd.SetOut(&out)
+	versionCmd.Run(versionCmd, nil)
+	if want := "slashmetrics 1.2.3 (commit abc123, built 2024-01-02T03:04:05Z)\n"; out.String() != want {
+		t.Fatalf("expected %q, got %q", want, out.String())
+	}
+	if ua := defaultUserAgent(); ua != "slashmetrics/1.2.3" {
+		t.Fatalf("expected the version in the User-Agent, got %q", ua)
+	}
+	if !strings.HasPrefix(rootCmd.Version, "slashmetrics ") {
+		t.Fatalf("expected --version to print the build, got %q", rootCmd.Version)
+	}
+}
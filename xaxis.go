@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/NimbleMarkets/ntcharts/linechart/timeserieslinechart"
+)
+
+// formatOffset formats how long ago a time was, e.g. "-45s", "-3m20s" or "-1h5m"
+func formatOffset(d time.Duration) string {
+	d = d.Round(time.Second)
+	switch {
+	case d <= 0:
+		return "0s"
+	case d < time.Minute:
+		return fmt.Sprintf("-%ds", int(d.Seconds()))
+	case d < time.Hour:
+		if s := int(d.Seconds()) % 60; s != 0 {
+			return fmt.Sprintf("-%dm%ds", int(d.Minutes()), s)
+		}
+		return fmt.Sprintf("-%dm", int(d.Minutes()))
+	default:
+		if m := int(d.Minutes()) % 60; m != 0 {
+			return fmt.Sprintf("-%dh%dm", int(d.Hours()), m)
+		}
+		return fmt.Sprintf("-%dh", int(d.Hours()))
+	}
+}
+
+// relativeTimeLabelFormatter labels the time axis with the offset from ref
+func relativeTimeLabelFormatter(ref time.Time) func(int, float64) string {
+	return func(_ int, v float64) string {
+		return formatOffset(ref.Sub(time.Unix(int64(v), 0)))
+	}
+}
+
+// applyXScale labels the time axis with wall-clock times, or with the offset
+// from the latest scrape (or now, before the first one)
+func (m *Model) applyXScale() {
+	if !m.xRelative {
+		m.chart.XLabelFormatter = timeserieslinechart.HourTimeLabelFormatter()
+		return
+	}
+	ref := m.lastUpdate
+	if ref.IsZero() {
+		ref = time.Now()
+	}
+	m.chart.XLabelFormatter = relativeTimeLabelFormatter(ref)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatOffset(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0s"},
+		{-time.Second, "0s"},
+		{30 * time.Second, "-30s"},
+		{time.Minute, "-1m"},
+		{200 * time.Second, "-3m20s"},
+		{time.Hour, "-1h"},
+		{65 * time.Minute, "-1h5m"},
+	}
+	for _, tt := range tests {
+		if got := formatOffset(tt.d); got != tt.want {
+			t.Errorf("formatOffset(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestRelativeTimeLabelFormatter(t *testing.T) {
+	ref := time.Unix(1000, 0)
+	formatter := relativeTimeLabelFormatter(ref)
+	if got := formatter(0, 940); got != "-1m" {
+		t.Fatalf("expected -1m, got %q", got)
+	}
+	if got := formatter(0, 1000); got != "0s" {
+		t.Fatalf("expected 0s, got %q", got)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+// fixedBound returns a bound fixed by --y-min or --y-max on the scale of the chart
+func (m *Model) fixedBound(bound *float64) (float64, bool) {
+	if bound == nil {
+		return 0, false
+	}
+	return m.chartValue(*bound)
+}
+
+// yBounds returns the Y range for the given value bounds, keeping the bounds
+// fixed by --y-min and --y-max
+func (m *Model) yBounds(minVal, maxVal float64) (float64, float64) {
+	minY, maxY := yRangeFor(minVal, maxVal)
+	if lo, ok := m.fixedBound(m.yMin); ok {
+		minY = lo
+	}
+	hi, fixedMax := m.fixedBound(m.yMax)
+	if fixedMax {
+		maxY = hi
+	}
+
+	// A single fixed bound may leave no room for the values on the other side
+	if maxY <= minY {
+		if !fixedMax {
+			maxY = minY + 1
+		} else {
+			minY = maxY - 1
+		}
+	}
+	return minY, maxY
+}
+
+// setYRange sets both the expected and the displayed Y range of the chart
+func (m *Model) setYRange(minY, maxY float64) {
+	// The chart would only stretch its range to the exact value of a spike,
+	// so expanding the range is left to expandYRange
+	m.chart.AutoMinY = false
+	m.chart.AutoMaxY = false
+	m.chart.SetYRange(minY, maxY)
+	m.chart.SetViewYRange(minY, maxY)
+	m.yRangeSet = true
+}
+
+// plottedBounds returns the smallest and largest value of the lines on the chart
+func (m *Model) plottedBounds() (minVal, maxVal float64, ok bool) {
+	for _, line := range m.chartLines() {
+		for _, point := range line.points {
+			if !ok || point.Value < minVal {
+				minVal = point.Value
+			}
+			if !ok || point.Value > maxVal {
+				maxVal = point.Value
+			}
+			ok = true
+		}
+	}
+	return minVal, maxVal, ok
+}
+
+// expandYRange widens the Y range when plotted values fall outside of it, so
+// spikes aren't clipped. Bounds fixed by --y-min and --y-max stay as they are.
+func (m *Model) expandYRange() {
+	if !m.yRangeSet {
+		return
+	}
+	minVal, maxVal, ok := m.plottedBounds()
+	if !ok {
+		return
+	}
+
+	viewMin, viewMax := m.chart.ViewMinY(), m.chart.ViewMaxY()
+	fitMin, fitMax := m.yBounds(minVal, maxVal)
+	minY, maxY := min(viewMin, fitMin), max(viewMax, fitMax)
+	if lo, ok := m.fixedBound(m.yMin); ok {
+		minY = lo
+	}
+	if hi, ok := m.fixedBound(m.yMax); ok {
+		maxY = hi
+	}
+	if minY == viewMin && maxY == viewMax {
+		return
+	}
+	m.setYRange(minY, maxY)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	zone "github.com/lrstanley/bubblezone"
+)
+
+func TestYRangeForNegativeValues(t *testing.T) {
+	minY, maxY := yRangeFor(-10, -5)
+	if minY != -11 || maxY != -4.5 {
+		t.Fatalf("expected a margin around negative values, got %v to %v", minY, maxY)
+	}
+}
+
+func TestYBoundsFixed(t *testing.T) {
+	lo, hi := 0.0, 100.0
+	m := Model{yMin: &lo}
+	if minY, maxY := m.yBounds(10, 20); minY != 0 || maxY != 22 {
+		t.Fatalf("expected the fixed lower bound, got %v to %v", minY, maxY)
+	}
+
+	m = Model{yMin: &lo, yMax: &hi}
+	if minY, maxY := m.yBounds(10, 200); minY != 0 || maxY != 100 {
+		t.Fatalf("expected both fixed bounds, got %v to %v", minY, maxY)
+	}
+
+	// Values below a fixed lower bound still leave a usable range
+	m = Model{yMin: &lo}
+	if minY, maxY := m.yBounds(-20, -10); minY != 0 || maxY <= minY {
+		t.Fatalf("expected a non-empty range, got %v to %v", minY, maxY)
+	}
+}
+
+func TestYRangeExpandsOnSpike(t *testing.T) {
+	zone.NewGlobal()
+	start := time.Unix(1000, 0)
+	scrape := func(m Model, i int, value float64) Model {
+		model, _ := m.Update(MetricsMsg{
+			Samples: []MetricSample{{FullName: `up{instance="a"}`, Value: value}},
+			Time:    start.Add(time.Duration(i) * time.Second),
+		})
+		return model.(Model)
+	}
+
+	m := NewModel("http://localhost", "up", time.Second)
+	m = scrape(m, 0, 10)
+	m = scrape(m, 1, 100)
+	if m.chart.ViewMaxY() <= 100 {
+		t.Fatalf("expected the range to expand above the spike, got %v", m.chart.ViewMaxY())
+	}
+	if m.chart.ViewMinY() != 9 {
+		t.Fatalf("expected the lower bound to stay, got %v", m.chart.ViewMinY())
+	}
+
+	hi := 50.0
+	m = NewModel("http://localhost", "up", time.Second)
+	m.yMax = &hi
+	m = scrape(m, 0, 10)
+	m = scrape(m, 1, 100)
+	if m.chart.ViewMaxY() != 50 || m.chart.MaxY() != 50 {
+		t.Fatalf("expected the fixed upper bound to stay, got %v", m.chart.ViewMaxY())
+	}
+}
+
+func TestYRangeFitsAfterHidingDominantSeries(t *testing.T) {
+	zone.NewGlobal()
+	start := time.Unix(1000, 0)
+	m := NewModel("http://localhost", "up", time.Second)
+	for i := range 3 {
+		model, _ := m.Update(MetricsMsg{
+			Samples: []MetricSample{
+				{FullName: `up{instance="big"}`, Value: 1000},
+				{FullName: `up{instance="small"}`, Value: float64(i + 1)},
+			},
+			Time: start.Add(time.Duration(i) * time.Second),
+		})
+		m = model.(Model)
+	}
+	if m.chart.ViewMaxY() < 1000 {
+		t.Fatalf("expected the range to include the dominant series, got %v", m.chart.ViewMaxY())
+	}
+
+	for i, series := range m.seriesList {
+		if series.name == `up{instance="big"}` {
+			m.setSeriesChecked(i, false)
+		}
+	}
+	m.redrawChart()
+	if m.chart.ViewMaxY() >= 1000 || m.chart.ViewMaxY() < 3 {
+		t.Fatalf("expected the range to fit the remaining series, got %v–%v", m.chart.ViewMinY(), m.chart.ViewMaxY())
+	}
+}